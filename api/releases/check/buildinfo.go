@@ -0,0 +1,235 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+// BuildInfo : how a Hyprland binary was built, as reported by
+// "Hyprland --version" (same text as "hyprctl version") or
+// "hyprctl version -j"
+type BuildInfo struct {
+	// Version : release version, e.g. 0.45.2
+	Version string `json:"version,omitempty"`
+	// Tag : git describe of the build, e.g. v0.41.2-9-g918d8340
+	Tag    string `json:"tag,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// Branch : empty for release tarballs
+	Branch string `json:"branch,omitempty"`
+	// Dirty : built from a tree with local changes
+	Dirty         bool   `json:"dirty"`
+	CommitMessage string `json:"commit_message,omitempty"`
+	// Commits : commit count of the source tree
+	Commits int `json:"commits,omitempty"`
+	// Date : commit date of the build; zero when not reported
+	Date time.Time `json:"date"`
+	// Libraries : versions of the hypr* libraries it was built against
+	Libraries Libraries `json:"libraries"`
+	// Flags : build flags (debug, no xwayland, ...)
+	Flags []string `json:"flags,omitempty"`
+}
+
+// Libraries : library versions Hyprland was built against; empty when the
+// banner does not list them (before 0.45)
+type Libraries struct {
+	Aquamarine   string `json:"aquamarine,omitempty"`
+	Hyprutils    string `json:"hyprutils,omitempty"`
+	Hyprlang     string `json:"hyprlang,omitempty"`
+	Hyprcursor   string `json:"hyprcursor,omitempty"`
+	Hyprgraphics string `json:"hyprgraphics,omitempty"`
+}
+
+var (
+	// "Hyprland 0.45.2 built from branch  at commit 12f9a0d... (version: bump to 0.45.2)."
+	// "Hyprland, built from branch main at commit 9ecbd4e... dirty (config: fix ...)."
+	bannerHead = regexp.MustCompile(`(?m)^Hyprland(?:,| v?(\S+)) built from branch (\S*)\s*at commit ([0-9a-f]{7,40})( dirty)?\s*(?:\((.*)\))?\.?\s*$`)
+	bannerDate = regexp.MustCompile(`(?m)^Date:\s*(.+?)\s*$`)
+	bannerTag  = regexp.MustCompile(`(?m)^Tag:\s*([^,\s]+)(?:,\s*commits:\s*(\d+))?`)
+	bannerLib  = regexp.MustCompile(`(?m)^\s*(?:built against\s+)?(aquamarine|hyprutils|hyprlang|hyprcursor|hyprgraphics)\s+v?(\S+)\s*$`)
+	bannerFlag = regexp.MustCompile(`(?m)^flags(?: set)?:.*$`)
+)
+
+// dateLayouts : commit date formats of the banner across releases
+// (whitespace is collapsed before parsing)
+var dateLayouts = []string{
+	"Mon Jan 2 15:04:05 2006",
+	"Mon Jan 2 15:04:05 2006 -0700",
+	time.RFC3339,
+}
+
+// ParseBuildInfo : build info from the "Hyprland --version" or
+// "hyprctl version" banner
+func ParseBuildInfo(banner string) (BuildInfo, error) {
+	var b BuildInfo
+	banner = strings.ReplaceAll(banner, "\r\n", "\n")
+	head := bannerHead.FindStringSubmatch(banner)
+	tag := bannerTag.FindStringSubmatch(banner)
+	if head == nil && tag == nil {
+		return b, fmt.Errorf("not a Hyprland version banner: %q", strings.SplitN(strings.TrimSpace(banner), "\n", 2)[0])
+	}
+	if head != nil {
+		b.Version = head[1]
+		b.Branch = head[2]
+		b.Commit = head[3]
+		b.Dirty = head[4] != ""
+		b.CommitMessage = head[5]
+	}
+	if tag != nil {
+		b.Tag = tag[1]
+		b.Commits, _ = strconv.Atoi(tag[2])
+	}
+	if m := bannerDate.FindStringSubmatch(banner); m != nil {
+		b.Date = parseBuildDate(m[1])
+	}
+	for _, m := range bannerLib.FindAllStringSubmatch(banner, -1) {
+		b.Libraries.set(m[1], m[2])
+	}
+	if loc := bannerFlag.FindStringIndex(banner); loc != nil {
+		for _, line := range strings.Split(banner[loc[1]:], "\n")[1:] {
+			if line = strings.TrimSpace(line); line == "" {
+				break
+			}
+			b.Flags = append(b.Flags, line)
+		}
+	}
+	b.fillVersion()
+	return b, nil
+}
+
+// ParseBuildInfoJSON : build info from "hyprctl version -j"
+func ParseBuildInfoJSON(data []byte) (BuildInfo, error) {
+	var raw struct {
+		Branch            string          `json:"branch"`
+		Commit            string          `json:"commit"`
+		Version           string          `json:"version"`
+		Dirty             bool            `json:"dirty"`
+		CommitMessage     string          `json:"commit_message"`
+		CommitDate        string          `json:"commit_date"`
+		Tag               string          `json:"tag"`
+		Commits           json.RawMessage `json:"commits"`
+		BuildAquamarine   string          `json:"buildAquamarine"`
+		BuildHyprutils    string          `json:"buildHyprutils"`
+		BuildHyprlang     string          `json:"buildHyprlang"`
+		BuildHyprcursor   string          `json:"buildHyprcursor"`
+		BuildHyprgraphics string          `json:"buildHyprgraphics"`
+		Flags             []string        `json:"flags"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return BuildInfo{}, fmt.Errorf("invalid hyprctl version output: %v", err)
+	}
+	if raw.Commit == "" && raw.Tag == "" {
+		return BuildInfo{}, fmt.Errorf("invalid hyprctl version output: no commit or tag")
+	}
+	b := BuildInfo{
+		Version:       raw.Version,
+		Tag:           raw.Tag,
+		Commit:        raw.Commit,
+		Branch:        raw.Branch,
+		Dirty:         raw.Dirty,
+		CommitMessage: raw.CommitMessage,
+		Date:          parseBuildDate(raw.CommitDate),
+		Libraries: Libraries{
+			Aquamarine:   raw.BuildAquamarine,
+			Hyprutils:    raw.BuildHyprutils,
+			Hyprlang:     raw.BuildHyprlang,
+			Hyprcursor:   raw.BuildHyprcursor,
+			Hyprgraphics: raw.BuildHyprgraphics,
+		},
+		Flags: raw.Flags,
+	}
+	// commits is a string in some releases and a number in others
+	b.Commits, _ = strconv.Atoi(strings.Trim(string(raw.Commits), `"`))
+	// "flags": [] means the same as a banner without flags
+	if len(b.Flags) == 0 {
+		b.Flags = nil
+	}
+	b.fillVersion()
+	return b, nil
+}
+
+// fillVersion : Version from the tag when the banner has none (before 0.42)
+func (b *BuildInfo) fillVersion() {
+	if b.Version == "" && b.Tag != "" {
+		if v, err := version.Parse(b.Tag); err == nil {
+			v.Commits, v.Hash = 0, ""
+			b.Version = v.String()
+		}
+	}
+	b.Version = strings.TrimPrefix(b.Version, "v")
+}
+
+func (l *Libraries) set(name, ver string) {
+	switch name {
+	case "aquamarine":
+		l.Aquamarine = ver
+	case "hyprutils":
+		l.Hyprutils = ver
+	case "hyprlang":
+		l.Hyprlang = ver
+	case "hyprcursor":
+		l.Hyprcursor = ver
+	case "hyprgraphics":
+		l.Hyprgraphics = ver
+	}
+}
+
+// get : version of the named library; empty when not reported
+func (l Libraries) get(name string) string {
+	switch name {
+	case "aquamarine":
+		return l.Aquamarine
+	case "hyprutils":
+		return l.Hyprutils
+	case "hyprlang":
+		return l.Hyprlang
+	case "hyprcursor":
+		return l.Hyprcursor
+	case "hyprgraphics":
+		return l.Hyprgraphics
+	}
+	return ""
+}
+
+// each : libraries with a known version, in a fixed order
+func (l Libraries) each(fn func(name, ver string)) {
+	for _, name := range hyprLibraries {
+		if ver := l.get(name); ver != "" {
+			fn(name, ver)
+		}
+	}
+}
+
+func parseBuildDate(s string) time.Time {
+	s = strings.Join(strings.Fields(s), " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// buildInfo : build info of a component; hyprctl is asked for JSON first
+// (needs a running Hyprland), otherwise the --version banner is parsed.
+// nil for tools whose banner has no build info.
+func buildInfo(ctx context.Context, name, banner string) *BuildInfo {
+	if name == "hyprctl" {
+		if out, err := exec.CommandContext(ctx, name, "version", "-j").Output(); err == nil {
+			if b, err := ParseBuildInfoJSON(out); err == nil {
+				return &b
+			}
+		}
+	}
+	if b, err := ParseBuildInfo(banner); err == nil {
+		return &b
+	}
+	return nil
+}
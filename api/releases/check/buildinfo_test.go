@@ -0,0 +1,127 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseBuildInfo : testdata holds "Hyprland --version" banners
+// (*.banner) and "hyprctl version -j" output (*.json) of several releases
+func TestParseBuildInfo(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.DateTime, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	v0412 := BuildInfo{
+		Version:       "0.41.2",
+		Tag:           "v0.41.2-9-g918d8340",
+		Commit:        "918d8340afd652b011b937d29d5eea0be08467f5",
+		Branch:        "main",
+		CommitMessage: "[gha] Nix: update inputs",
+		Commits:       4915,
+		Date:          date("2024-06-26 08:23:43"),
+	}
+	v0452 := BuildInfo{
+		Version:       "0.45.2",
+		Tag:           "v0.45.2",
+		Commit:        "12f9a0d0b93f691d4d9923716557154d74777b0a",
+		CommitMessage: "version: bump to 0.45.2",
+		Commits:       5518,
+		Date:          date("2024-11-16 14:54:18"),
+		Libraries:     Libraries{Aquamarine: "0.5.0"},
+		Flags:         []string{"debug", "no xwayland"},
+	}
+	libs0481 := Libraries{Aquamarine: "0.8.0", Hyprlang: "0.6.0", Hyprutils: "0.5.2", Hyprcursor: "0.1.12", Hyprgraphics: "0.1.2"}
+
+	tests := []struct {
+		file string
+		want BuildInfo
+	}{
+		{
+			// before 0.42 the banner has no version and no date; it comes
+			// from the tag
+			file: "hyprland-0.34.0-dirty.banner",
+			want: BuildInfo{
+				Version:       "0.34.0",
+				Tag:           "v0.34.0-12-g9ecbd4e1",
+				Commit:        "9ecbd4e1f8f34d8c8b5e7e8b0bd9df8f2a3c11c0",
+				Branch:        "main",
+				Dirty:         true,
+				CommitMessage: "config: fix gaps",
+				Flags:         []string{"debug"},
+			},
+		},
+		{file: "hyprland-0.41.2.banner", want: v0412},
+		{file: "hyprland-0.41.2.json", want: v0412},
+		{file: "hyprland-0.45.2.banner", want: v0452},
+		{file: "hyprland-0.45.2.json", want: v0452},
+		{
+			// release tarballs report the tag as the branch from 0.48 on
+			file: "hyprland-0.48.1.banner",
+			want: BuildInfo{
+				Version:       "0.48.1",
+				Tag:           "v0.48.1",
+				Commit:        "29e2e59fdbab8ed2cc23a20e3c6043d5decb5cdc",
+				Branch:        "v0.48.1",
+				CommitMessage: "version: bump to 0.48.1",
+				Commits:       5937,
+				Date:          date("2025-03-28 16:16:07"),
+				Libraries:     libs0481,
+			},
+		},
+		{
+			file: "hyprland-0.48.1-dirty.json",
+			want: BuildInfo{
+				Version:       "0.48.1",
+				Tag:           "v0.48.1-3-g29e2e59f",
+				Commit:        "29e2e59fdbab8ed2cc23a20e3c6043d5decb5cdc",
+				Branch:        "main",
+				Dirty:         true,
+				CommitMessage: "version: bump to 0.48.1",
+				Commits:       5940,
+				Date:          date("2025-03-28 16:16:07"),
+				Libraries:     libs0481,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got BuildInfo
+			if strings.HasSuffix(tt.file, ".json") {
+				got, err = ParseBuildInfoJSON(data)
+			} else {
+				got, err = ParseBuildInfo(string(data))
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBuildInfoErrors(t *testing.T) {
+	for _, banner := range []string{"", "hyprlock v0.4.0", "Hyprpaper 0.7.1."} {
+		if b, err := ParseBuildInfo(banner); err == nil {
+			t.Errorf("ParseBuildInfo(%q) = %+v, want an error", banner, b)
+		}
+	}
+	for _, data := range []string{`not json`, `{"branch": "main"}`} {
+		if b, err := ParseBuildInfoJSON([]byte(data)); err == nil {
+			t.Errorf("ParseBuildInfoJSON(%s) = %+v, want an error", data, b)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package check
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+// Component : one entry of the component catalogue checked by
+// CheckHyprSystem. The built-in entries are in components.toml; files in
+// the components.d directories (see ComponentDirs) add entries or replace
+// the one with the same name:
+//
+//	[[component]]
+//	name = "hyprshot"                          # required, [A-Za-z0-9._+-]
+//	binary = "hyprshot"                        # default: name
+//	paths = ["/opt/hyprshot/bin"]              # searched after $PATH
+//	repo = "Gustash/Hyprshot"                  # owner/name or URL; latest release or tag
+//	packages = { arch = "hyprshot", gentoo = "gui-apps/hyprshot" }   # default: name
+//	version_command = ["hyprshot", "--version"] # default: [binary, "--version"]; [] for none
+//	version_regex = 'Hyprshot (\S+)'           # first group (or the match) is the version
+//
+// Libraries (library = true) have no binary; they are found when their
+// version_command, usually pkg-config, succeeds.
+type Component struct {
+	Name    string   `toml:"name" json:"name"`
+	Binary  string   `toml:"binary" json:"binary,omitempty"`
+	Paths   []string `toml:"paths" json:"paths,omitempty"`
+	Library bool     `toml:"library" json:"library,omitempty"`
+	Repo    string   `toml:"repo" json:"repo,omitempty"`
+	// Packages : package name per distro (arch, debian, fedora, void, gentoo)
+	Packages       map[string]string `toml:"packages" json:"packages,omitempty"`
+	VersionCommand []string          `toml:"version_command" json:"version_command,omitempty"`
+	VersionRegex   string            `toml:"version_regex" json:"version_regex,omitempty"`
+
+	// Origin : file the entry comes from; "builtin" for components.toml
+	Origin string `toml:"-" json:"origin"`
+
+	versionRe *regexp.Regexp
+}
+
+type catalogueFile struct {
+	Components []Component `toml:"component"`
+}
+
+//go:embed components.toml
+var builtinCatalogue string
+
+var validComponentName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// ComponentDirs : components.d directories, lowest precedence first
+func ComponentDirs() []string {
+	return []string{
+		"/usr/share/hypr-release/components.d",
+		"/etc/hypr-release/components.d",
+		filepath.Join(paths.ConfigDir(), "components.d"),
+	}
+}
+
+// Components : the built-in catalogue merged with every components.d file
+// (*.toml, in name order). A later entry replaces an earlier one with the
+// same name (case-insensitive) in place; new names are appended. Broken
+// files are skipped and reported in errs.
+func Components() (components []Component, errs []error) {
+	builtin, err := parseCatalogue(builtinCatalogue, "builtin")
+	if err != nil {
+		// components.toml is part of the binary
+		panic(err)
+	}
+	components = builtin
+	for _, dir := range ComponentDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		var files []string
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".toml") {
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			loaded, err := parseCatalogue(string(data), file)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, c := range loaded {
+				components = mergeComponent(components, c)
+			}
+		}
+	}
+	return components, errs
+}
+
+func parseCatalogue(data, origin string) ([]Component, error) {
+	var f catalogueFile
+	md, err := toml.Decode(data, &f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", origin, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("%s: unknown field %q", origin, undecoded[0].String())
+	}
+	for i := range f.Components {
+		c := &f.Components[i]
+		c.Origin = origin
+		if c.Binary == "" && !c.Library {
+			c.Binary = c.Name
+		}
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %v", origin, i+1, err)
+		}
+	}
+	return f.Components, nil
+}
+
+func (c *Component) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !validComponentName.MatchString(c.Name) {
+		return fmt.Errorf("invalid name %q: use letters, digits, '.', '_', '+' or '-'", c.Name)
+	}
+	if c.Library && len(c.VersionCommand) == 0 {
+		return fmt.Errorf("%s: a library needs a version_command", c.Name)
+	}
+	if c.Library && (c.Binary != "" || len(c.Paths) > 0) {
+		return fmt.Errorf("%s: a library has no binary or paths", c.Name)
+	}
+	for _, p := range c.Paths {
+		if !filepath.IsAbs(p) {
+			return fmt.Errorf("%s: path %q is not absolute", c.Name, p)
+		}
+	}
+	for distro := range c.Packages {
+		if _, ok := packageManagers[distro]; !ok {
+			return fmt.Errorf("%s: unknown distro %q in packages", c.Name, distro)
+		}
+	}
+	if c.VersionRegex != "" {
+		re, err := regexp.Compile(c.VersionRegex)
+		if err != nil {
+			return fmt.Errorf("%s: invalid version_regex: %v", c.Name, err)
+		}
+		c.versionRe = re
+	}
+	return nil
+}
+
+func mergeComponent(components []Component, c Component) []Component {
+	for i := range components {
+		if strings.EqualFold(components[i].Name, c.Name) {
+			components[i] = c
+			return components
+		}
+	}
+	return append(components, c)
+}
+
+// Package : package name of c on distro
+func (c Component) Package(distro string) string {
+	if p := c.Packages[distro]; p != "" {
+		return p
+	}
+	return c.Name
+}
+
+// lookPath : binary of c in $PATH, then in c.Paths
+func (c Component) lookPath() (string, error) {
+	if path, err := exec.LookPath(c.Binary); err == nil {
+		return path, nil
+	}
+	for _, dir := range c.Paths {
+		path := filepath.Join(dir, c.Binary)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in PATH", c.Binary)
+}
+
+// versionCommand : argv printing the version; a leading binary name is
+// replaced by the path it was found at. nil when c has none.
+func (c Component) versionCommand(path string) []string {
+	if c.VersionCommand == nil {
+		return []string{path, "--version"}
+	}
+	argv := append([]string(nil), c.VersionCommand...)
+	if len(argv) > 0 && path != "" && argv[0] == c.Binary {
+		argv[0] = path
+	}
+	if len(argv) == 0 {
+		return nil
+	}
+	return argv
+}
+
+// parseVersion : version in the output of the version command
+func (c Component) parseVersion(out string) (version.Version, error) {
+	if c.versionRe == nil {
+		return version.ParseBanner(out)
+	}
+	m := c.versionRe.FindStringSubmatch(out)
+	if m == nil {
+		return version.Version{Raw: out}, fmt.Errorf("%s: version_regex does not match %q", c.Name, strings.SplitN(out, "\n", 2)[0])
+	}
+	if len(m) > 1 {
+		return version.Parse(m[1])
+	}
+	return version.Parse(m[0])
+}
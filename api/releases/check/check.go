@@ -1,11 +1,15 @@
 package check
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+	"github.com/hyprcommunity/hypr-release/api/releases/mirror"
 	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
 )
 
@@ -17,53 +21,155 @@ type Release struct {
 	PublishedAt string `json:"publishedAt"`
 }
 
-// CheckAll : GH release varsa onu ana sürüm, git tag'i alt sürüm olarak kullanır.
-// GH release yoksa git tag ana sürüm olur.
-func CheckAll(dotfileName, repoPath string) (map[string]string, string, error) {
-	result := make(map[string]string)
+// ReleaseStatus : sürüm bilgisi ve yerel checkout'un upstream'e göre durumu
+type ReleaseStatus struct {
+	// VersionMain : forge release'i, yoksa git tag'i
+	VersionMain string `json:"version_main"`
+	// VersionBuild : git describe çıktısı
+	VersionBuild string `json:"version_build"`
+	// Source : VersionMain'in kaynağı (GitHub, GitLab, Gitea ya da git)
+	Source string `json:"source"`
+	// Branch : checkout edilen branch; detached ise "HEAD"
+	Branch string `json:"branch"`
+	Commit string `json:"commit"`
+	// Upstream : karşılaştırılan remote-tracking branch (ör. origin/main);
+	// çözülemezse boş kalır ve Ahead/Behind anlamsızdır
+	Upstream string `json:"upstream"`
+	// Ahead : yerelde olup upstream'de olmayan commit sayısı
+	Ahead int `json:"ahead"`
+	// Behind : upstream'de olup yerelde olmayan commit sayısı
+	Behind int `json:"behind"`
+	// Diverged : hem ileride hem geride
+	Diverged bool `json:"diverged"`
+	// Dirty : çalışma ağacında commit edilmemiş değişiklik var
+	Dirty bool `json:"dirty"`
+	// Fetched : fetch başarılı; değilse son fetch'teki durum raporlanır
+	Fetched bool `json:"fetched"`
+}
+
+// CheckAll : forge'da (GitHub, GitLab, Gitea) release varsa onu ana sürüm,
+// git tag'i alt sürüm olarak kullanır; release yoksa git tag ana sürüm olur.
+// Önce remote fetch edilir, sonra checkout edilen branch'in upstream'i
+// (yoksa registry branch'i) ile ahead/behind ve dirty durumu hesaplanır.
+// repoPath boşsa depo önbelleği (mirror) kullanılır; önbellek yeniden
+// checkout edilmez, kurulan branch/ref olduğu gibi kalır.
+func CheckAll(dotfileName, repoPath string) (ReleaseStatus, string, error) {
+	var status ReleaseStatus
 	var output strings.Builder
 
-	d := summaryofversion.GetDotfileByName(dotfileName)
-	if d == nil {
-		return nil, "", fmt.Errorf("dotfile not found: %s", dotfileName)
+	found := summaryofversion.GetDotfileByName(dotfileName)
+	if found == nil {
+		return status, "", fmt.Errorf("dotfile not found: %s", dotfileName)
+	}
+	// moved_to'lu girdide her şey yeni depoya bakar
+	d, _ := found.Moved()
+	cached := repoPath == ""
+	repoPath, err := repoDir(d, repoPath)
+	if err != nil {
+		return status, "", err
 	}
 
 	output.WriteString(fmt.Sprintf("🔍 Checking repository: %s (%s)\n", d.Name, d.Repo))
 
-	// GH komutu
-	ghCmd := exec.Command("gh", "release", "list", "--repo", d.Repo, "--limit", "3", "--json", "tagName,publishedAt")
-	data, err := ghCmd.Output()
+	// önce fetch
+	ctx, git := context.Background(), gitbackend.Default()
+	if cached {
+		err = mirror.Fetch(d, mirror.Options{})
+	} else {
+		err = git.Fetch(ctx, repoPath, gitbackend.FetchOptions{})
+	}
+	status.Fetched = err == nil
 	if err != nil {
-		output.WriteString(fmt.Sprintf("⚠️ gh release list failed: %v\n", err))
+		output.WriteString(fmt.Sprintf("⚠️ fetch failed, comparing with the last fetched state: %v\n", err))
 	}
 
+	// forge releases
 	var releases []Release
-	_ = json.Unmarshal(data, &releases)
+	status.Source = "git"
+	if forge, err := ForgeFor(d.Repo, d.Forge); err != nil {
+		output.WriteString(fmt.Sprintf("⚠️ %v\n", err))
+	} else {
+		status.Source = ForgeName(forge.Kind())
+		releases, err = forge.Releases(ctx, 3)
+		if err != nil && !errors.Is(err, ErrNoReleases) {
+			output.WriteString(fmt.Sprintf("⚠️ %s releases unavailable: %v\n", status.Source, err))
+		}
+		if l := forge.RateLimit(); l.Limit > 0 && l.Remaining < 10 {
+			output.WriteString(fmt.Sprintf("⚠️ %s API: %d requests left until %s\n", status.Source, l.Remaining, l.Reset.Local().Format("15:04")))
+		}
+	}
 
 	// git tag
-	tagCmd := exec.Command("git", "-C", repoPath, "describe", "--tags", "--abbrev=7", "--always")
-	tagOut, _ := tagCmd.Output()
-	gitTag := strings.TrimSpace(string(tagOut))
+	gitTag, _ := git.Describe(ctx, repoPath, "HEAD")
 
 	if len(releases) > 0 {
-		mainVer := releases[0].Tag
-		buildVer := gitTag
-		result["version_main"] = mainVer
-		result["version_build"] = buildVer
-		output.WriteString(fmt.Sprintf("Main version (GitHub): %s\n", mainVer))
-		output.WriteString(fmt.Sprintf("Build version (git):  %s\n", buildVer))
+		status.VersionMain = releases[0].Tag
+		status.VersionBuild = gitTag
+		output.WriteString(fmt.Sprintf("Main version (%s): %s\n", status.Source, status.VersionMain))
+		output.WriteString(fmt.Sprintf("Build version (git):  %s\n", status.VersionBuild))
+	} else {
+		status.Source = "git"
+		status.VersionMain = gitTag
+		status.VersionBuild = gitTag
+		output.WriteString(fmt.Sprintf("Main version (git): %s\n", status.VersionMain))
+	}
+
+	// upstream karşılaştırması
+	status.Commit, _ = git.Resolve(ctx, repoPath, "HEAD")
+	status.Branch, _ = git.CurrentBranch(ctx, repoPath)
+	status.Upstream = upstream(ctx, git, repoPath, d.Branch)
+	if status.Upstream == "" {
+		output.WriteString(fmt.Sprintf("⚠️ no upstream found for %s (tried the tracked branch and origin/%s)\n", status.Branch, d.Branch))
 	} else {
-		mainVer := gitTag
-		result["version_main"] = mainVer
-		result["version_build"] = mainVer
-		output.WriteString(fmt.Sprintf("Main version (git): %s\n", mainVer))
+		status.Ahead, _ = git.RevListCount(ctx, repoPath, status.Upstream, "HEAD")
+		status.Behind, _ = git.RevListCount(ctx, repoPath, "HEAD", status.Upstream)
+		status.Diverged = status.Ahead > 0 && status.Behind > 0
+		output.WriteString(fmt.Sprintf("Branch: %s → %s\n", status.Branch, status.Upstream))
+		output.WriteString(fmt.Sprintf("Commits behind %s: %d\n", status.Upstream, status.Behind))
+		output.WriteString(fmt.Sprintf("Commits ahead of %s: %d\n", status.Upstream, status.Ahead))
+		if status.Diverged {
+			output.WriteString(fmt.Sprintf("⚠️ %s has diverged from %s\n", status.Branch, status.Upstream))
+		}
 	}
 
-	// commit farkı
-	diffCmd := exec.Command("git", "-C", repoPath, "rev-list", "HEAD..origin/master", "--count")
-	diffOut, _ := diffCmd.Output()
-	result["commits_behind"] = strings.TrimSpace(string(diffOut))
-	output.WriteString(fmt.Sprintf("Commits behind origin/master: %s\n", result["commits_behind"]))
+	if status.Dirty, err = git.Dirty(ctx, repoPath); err != nil {
+		output.WriteString(fmt.Sprintf("⚠️ cannot read working tree status: %v\n", err))
+	} else if status.Dirty {
+		output.WriteString("⚠️ working tree has uncommitted changes\n")
+	}
+
+	return status, output.String(), nil
+}
+
+// upstream : branch'in takip ettiği remote-tracking branch, yoksa
+// origin/<registry branch>; ikisi de çözülemezse boş
+func upstream(ctx context.Context, git gitbackend.Backend, repoPath, branch string) string {
+	if u, err := git.Upstream(ctx, repoPath); err == nil && u != "" {
+		if _, err := git.Resolve(ctx, repoPath, u); err == nil {
+			return u
+		}
+	}
+	if _, err := git.Resolve(ctx, repoPath, "refs/remotes/origin/"+branch); err == nil {
+		return "origin/" + branch
+	}
+	return ""
+}
+
+// repoDir : repoPath if given, otherwise the repository cache of d, cloned
+// on first use. An existing cache is not checked out again, so it stays at
+// the branch or ref that was installed.
+func repoDir(d summaryofversion.Dotfile, repoPath string) (string, error) {
+	if repoPath != "" {
+		return repoPath, nil
+	}
+	d, _ = d.Moved()
+	if dir := mirror.Path(d.Name); isRepo(dir) {
+		return dir, nil
+	}
+	return mirror.Sync(d, "", mirror.Options{})
+}
 
-	return result, output.String(), nil
+func isRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
 }
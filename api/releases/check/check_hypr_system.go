@@ -2,10 +2,16 @@ package check
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
 )
 
 type HyprComponent struct {
@@ -17,128 +23,344 @@ type HyprComponent struct {
 	UpdateAvailable bool
 	Source          string
 	PackageSource   string
+	// Build : Hyprland build info (tag, commit, libraries); nil for tools
+	// whose --version output has none
+	Build *BuildInfo
+	// ABI : library versions against the build (only for hyprland)
+	ABI *ABIReport
+	// Errors : probes that failed or timed out; the matching fields are
+	// "unknown"
+	Errors []string
+}
+
+// Varsayılan eşzamanlılık ve probe zaman aşımı
+const (
+	DefaultSystemWorkers      = 4
+	DefaultSystemProbeTimeout = 15 * time.Second
+)
+
+// SystemOptions : CheckHyprSystem ayarları
+type SystemOptions struct {
+	// Plan doluysa sistem meta dosyası yazılmaz, plana eklenir (dry-run).
+	Plan *plan.Plan
+	// Workers : aynı anda kontrol edilen bileşen sayısı; 0 ise DefaultSystemWorkers
+	Workers int
+	// ProbeTimeout : her komut / ağ sorgusu için süre sınırı; 0 ise
+	// DefaultSystemProbeTimeout
+	ProbeTimeout time.Duration
 }
 
-func CheckHyprSystem() ([]HyprComponent, string, error) {
-	components := map[string]string{
-		"hyprland":  "hyprwm/Hyprland",
-		"hyprctl":   "hyprwm/Hyprland",
-		"hyprpaper": "hyprwm/hyprpaper",
-		"hypridle":  "hyprwm/hypridle",
-		"hyprlock":  "hyprwm/hyprlock",
+func (o SystemOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
 	}
-	var results []HyprComponent
+	return DefaultSystemWorkers
+}
+
+func (o SystemOptions) probeTimeout() time.Duration {
+	if o.ProbeTimeout > 0 {
+		return o.ProbeTimeout
+	}
+	return DefaultSystemProbeTimeout
+}
+
+// CheckHyprSystem : bileşen kataloğundaki (bkz. Components) Hyprland
+// bileşenlerini kontrol eder ve /etc/hyprland-system-release dosyasını yazar.
+// Bileşenler opts.Workers işçiyle eşzamanlı, her probe opts.ProbeTimeout
+// sınırıyla çalışır; sonuçlar ve log katalog sırasındadır. Başarısız probe'lar
+// bileşenin Errors alanına yazılır. ctx iptal edilirse o ana kadarki
+// sonuçlar ctx hatasıyla döner ve meta dosyası yazılmaz.
+func CheckHyprSystem(ctx context.Context, opts SystemOptions) ([]HyprComponent, string, error) {
+	catalogue, errs := Components()
 	var log bytes.Buffer
+	for _, err := range errs {
+		log.WriteString(fmt.Sprintf("⚠️ components: %v\n", err))
+	}
+
+	type probed struct {
+		component *HyprComponent
+		log       string
+	}
+	out := make([]probed, len(catalogue))
+	remotes := &remoteVersions{memo: make(map[string]*remoteVersion)}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.workers(), len(catalogue)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c, line := checkComponent(ctx, catalogue[i], opts.probeTimeout(), remotes)
+				out[i] = probed{c, line}
+			}
+		}()
+	}
+feed:
+	for i := range catalogue {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-	for name, repo := range components {
-		pathCmd := exec.Command("which", name)
-		pathOut, err := pathCmd.Output()
+	// boş sonuç JSON'da null değil [] olsun
+	results := []HyprComponent{}
+	for _, p := range out {
+		log.WriteString(p.log)
+		if p.component != nil {
+			results = append(results, *p.component)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		log.WriteString(fmt.Sprintf("⚠️ system check interrupted: %v\n", err))
+		return results, log.String(), err
+	}
+
+	checkHyprLibraries(results, &log)
+
+	if opts.Plan != nil {
+		PlanHyprSystemMeta(opts.Plan, results)
+	} else if err := WriteHyprSystemMeta(results); err != nil {
+		log.WriteString(fmt.Sprintf("⚠️ system meta write failed: %v\n", err))
+	}
+
+	appendReleaseInfo(&log, catalogue)
+	return results, log.String(), nil
+}
+
+// checkComponent : tek bileşenin probe'ları; bileşen bulunamazsa nil döner
+func checkComponent(ctx context.Context, c Component, timeout time.Duration, remotes *remoteVersions) (*HyprComponent, string) {
+	name := c.Name
+	if err := ctx.Err(); err != nil {
+		return nil, ""
+	}
+	var log strings.Builder
+	var probeErrs []string
+	probeFailed := func(probe string, err error) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		probeErrs = append(probeErrs, fmt.Sprintf("%s: %v", probe, err))
+		log.WriteString(fmt.Sprintf("⚠️ %s %s: %v\n", name, probe, err))
+	}
+
+	var path string
+	if !c.Library {
+		p, err := c.lookPath()
 		if err != nil {
-			log.WriteString(fmt.Sprintf("⚠️ %s not found in PATH\n", name))
-			continue
+			return nil, fmt.Sprintf("⚠️ %s not found in PATH\n", name)
 		}
-		path := strings.TrimSpace(string(pathOut))
+		path = p
+	}
 
-		verCmd := exec.Command(name, "--version")
-		verOut, _ := verCmd.Output()
-		localVer := strings.TrimSpace(string(verOut))
-		if localVer == "" {
-			localVer = "unknown"
+	var localVer string
+	if argv := c.versionCommand(path); argv != nil {
+		pctx, cancel := context.WithTimeout(ctx, timeout)
+		verOut, err := exec.CommandContext(pctx, argv[0], argv[1:]...).Output()
+		timedOut := pctx.Err() != nil
+		cancel()
+		switch {
+		case timedOut:
+			probeFailed("version", pctx.Err())
+		case err != nil && c.Library:
+			return nil, fmt.Sprintf("⚠️ %s not found (%s)\n", name, strings.Join(argv, " "))
 		}
+		localVer = strings.TrimSpace(string(verOut))
+	}
+	if localVer == "" {
+		localVer = "unknown"
+	}
+	pctx, cancel := context.WithTimeout(ctx, timeout)
+	build := buildInfo(pctx, name, localVer)
+	cancel()
 
-		remoteVer := getRemoteVersion(repo)
-		pkgVer, pkgSrc := getPackageManagerVersion(name)
+	remoteVer := "unknown"
+	if c.Repo != "" {
+		v, err := remotes.get(ctx, c.Repo, timeout)
+		if err != nil {
+			probeFailed("remote", err)
+		} else {
+			remoteVer = v
+		}
+	}
+	pctx, cancel = context.WithTimeout(ctx, timeout)
+	pkgVer, pkgSrc, err := getPackageManagerVersion(pctx, c)
+	cancel()
+	if err != nil {
+		probeFailed("package", err)
+	}
 
-		updateAvailable := false
-		if remoteVer != "unknown" && remoteVer != localVer {
-			updateAvailable = true
-		} else if pkgVer != "unknown" && pkgVer != localVer {
+	// sürümler ayrıştırılıp sıralanarak karşılaştırılır; yerel sürüm
+	// okunamazsa güncelleme bildirilmez
+	updateAvailable, newest := false, remoteVer
+	if local, err := c.parseVersion(localVer); err == nil {
+		if remote, err := version.Parse(remoteVer); err == nil && remote.Newer(local) {
 			updateAvailable = true
+		} else if pkg, err := version.Parse(pkgVer); err == nil && pkg.Newer(local) {
+			updateAvailable, newest = true, pkgVer
 		}
+		localVer = local.String()
+	}
 
-		results = append(results, HyprComponent{
-			Name:            name,
-			Version:         localVer,
-			Path:            path,
-			RemoteVersion:   remoteVer,
-			PackageVersion:  pkgVer,
-			UpdateAvailable: updateAvailable,
-			Source:          repo,
-			PackageSource:   pkgSrc,
-		})
-
-		if updateAvailable {
-			log.WriteString(fmt.Sprintf("⬆️  %s update available: %s → %s (%s)\n", name, localVer, remoteVer, pkgSrc))
-		} else {
-			log.WriteString(fmt.Sprintf("✅ %s up to date (%s)\n", name, localVer))
+	if updateAvailable {
+		log.WriteString(fmt.Sprintf("⬆️  %s update available: %s → %s (%s)\n", name, localVer, newest, pkgSrc))
+	} else {
+		log.WriteString(fmt.Sprintf("✅ %s up to date (%s)\n", name, localVer))
+	}
+	return &HyprComponent{
+		Name:            name,
+		Version:         localVer,
+		Path:            path,
+		RemoteVersion:   remoteVer,
+		PackageVersion:  pkgVer,
+		UpdateAvailable: updateAvailable,
+		Source:          c.Repo,
+		PackageSource:   pkgSrc,
+		Build:           build,
+		Errors:          probeErrs,
+	}, log.String()
+}
+
+// checkHyprLibraries : Hyprland ikilisinin kütüphanelerini kontrol eder;
+// derleme bilgisi, çalışan Hyprland'i bildiren hyprctl'den tercih edilir
+func checkHyprLibraries(results []HyprComponent, log *bytes.Buffer) {
+	var hyprland *HyprComponent
+	var build *BuildInfo
+	for i := range results {
+		c := &results[i]
+		switch c.Name {
+		case "hyprland":
+			hyprland = c
+			if build == nil {
+				build = c.Build
+			}
+		case "hyprctl":
+			if c.Build != nil && c.Build.Libraries != (Libraries{}) {
+				build = c.Build
+			}
 		}
 	}
+	if hyprland == nil || hyprland.Path == "" {
+		return
+	}
+	report, err := CheckLibraries(hyprland.Path, build)
+	if err != nil {
+		log.WriteString(fmt.Sprintf("⚠️ library check failed: %v\n", err))
+		return
+	}
+	hyprland.ABI = report
+	for _, l := range report.Libraries {
+		switch l.ABI {
+		case ABIMismatch, ABIMissing:
+			log.WriteString(fmt.Sprintf("⚠️ %s ABI %s: %s\n", l.Name, l.ABI, l.Reason))
+		case ABIOk:
+			log.WriteString(fmt.Sprintf("✅ %s ABI ok (%s)\n", l.Name, libraryLabel(l)))
+		}
+	}
+	for _, lib := range report.Missing {
+		log.WriteString(fmt.Sprintf("⚠️ %s needs %s, which is not installed\n", hyprland.Name, lib))
+	}
+}
 
-	if err := WriteHyprSystemMeta(results); err != nil {
-		log.WriteString(fmt.Sprintf("⚠️ system meta write failed: %v\n", err))
+func libraryLabel(l LibraryStatus) string {
+	switch {
+	case l.Version != "" && l.Needed != "":
+		return l.Version + ", " + l.Needed
+	case l.Version != "":
+		return l.Version
 	}
+	return l.Needed
+}
 
-	appendReleaseInfo()
-	return results, log.String(), nil
+// remoteVersions : aynı repo'yu paylaşan bileşenler (hyprland, hyprctl)
+// için forge bir kez sorgulanır
+type remoteVersions struct {
+	mu   sync.Mutex
+	memo map[string]*remoteVersion
 }
 
-// github / git fallback
-func getRemoteVersion(repo string) string {
-	ghCmd := exec.Command("gh", "release", "list", "--repo", repo, "--limit", "1", "--json", "tagName")
-	data, err := ghCmd.Output()
-	if err == nil {
-		var rel []map[string]string
-		_ = json.Unmarshal(data, &rel)
-		if len(rel) > 0 {
-			return rel[0]["tagName"]
+type remoteVersion struct {
+	once    sync.Once
+	version string
+	err     error
+}
+
+func (r *remoteVersions) get(ctx context.Context, repo string, timeout time.Duration) (string, error) {
+	r.mu.Lock()
+	rv, ok := r.memo[repo]
+	if !ok {
+		rv = &remoteVersion{}
+		r.memo[repo] = rv
+	}
+	r.mu.Unlock()
+	rv.once.Do(func() {
+		pctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		rv.version, rv.err = getRemoteVersion(pctx, repo)
+	})
+	return rv.version, rv.err
+}
+
+// forge release, yoksa en yeni tag
+func getRemoteVersion(ctx context.Context, repo string) (string, error) {
+	forge, err := ForgeFor(repo, "")
+	if err != nil {
+		return "", err
+	}
+	v, err := LatestVersion(ctx, forge)
+	if err == nil && v != "" {
+		return v, nil
+	}
+	if forge.Kind() != ForgeGit && ctx.Err() == nil {
+		// API erişilemezse tag'ler git üzerinden okunur
+		if v, gitErr := LatestVersion(ctx, NewGitForge(gitURL(repo))); gitErr == nil && v != "" {
+			return v, nil
 		}
 	}
-	gitCmd := exec.Command("git", "ls-remote", "--tags", fmt.Sprintf("https://github.com/%s.git", repo))
-	gitOut, err := gitCmd.Output()
 	if err == nil {
-		lines := strings.Split(strings.TrimSpace(string(gitOut)), "\n")
-		if len(lines) > 0 {
-			last := lines[len(lines)-1]
-			if idx := strings.LastIndex(last, "/"); idx != -1 {
-				return strings.TrimSpace(last[idx+1:])
-			}
-		}
+		err = fmt.Errorf("no releases or tags")
 	}
-	return "unknown"
+	return "", err
 }
 
-// distro paket yöneticilerini kontrol eder
-func getPackageManagerVersion(pkg string) (string, string) {
-	candidates := map[string][]string{
-		"arch":   {"pacman", "-Si", pkg},
-		"debian": {"apt-cache", "policy", pkg},
-		"fedora": {"dnf", "info", pkg},
-		"void":   {"xbps-query", "-R", pkg},
-		"gentoo": {"emerge", "-pv", pkg},
-	}
-	for distro, args := range candidates {
-		cmd := exec.Command(args[0], args[1:]...)
-		out, err := cmd.Output()
-		if err == nil {
-			text := string(out)
-			if strings.Contains(text, "Version") || strings.Contains(text, "Version :") {
-				for _, line := range strings.Split(text, "\n") {
-					if strings.Contains(strings.ToLower(line), "version") {
-						return strings.TrimSpace(strings.Split(line, ":")[1]), distro
-					}
-				}
-			}
+// distro paket yöneticilerini, bileşenin o distrodaki paket adıyla kontrol
+// eder; kurulu olmayan paket yöneticileri ve paketi bilmeyenler hata
+// sayılmaz. Çıktısı okunamayan paket yöneticisi ya da ctx'in süresinin
+// dolması hata döner.
+func getPackageManagerVersion(ctx context.Context, c Component) (string, string, error) {
+	var parseErr error
+	for _, distro := range packageManagerDistros {
+		if err := ctx.Err(); err != nil {
+			return "unknown", "none", err
 		}
+		pm, pkg := packageManagers[distro], c.Package(distro)
+		args := append(append([]string(nil), pm.query...), pkg)
+		out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if err != nil {
+			continue
+		}
+		v, err := pm.parse(string(out), pkg)
+		if err != nil {
+			parseErr = err
+			continue
+		}
+		if v != "" {
+			return v, distro, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "unknown", "none", err
 	}
-	return "unknown", "none"
+	return "unknown", "none", parseErr
 }
 
-// appendReleaseInfo provides a small log summary.
-func appendReleaseInfo() {
-	var buf strings.Builder
-	buf.WriteString("\n📦 Hyprland Component Summary:\n")
-	for _, c := range []string{"hyprland", "hyprctl", "hyprpaper", "hypridle", "hyprlock"} {
-		buf.WriteString(fmt.Sprintf("- %s checked.\n", c))
+// appendReleaseInfo appends a small summary to the log.
+func appendReleaseInfo(log *bytes.Buffer, catalogue []Component) {
+	log.WriteString("\n📦 Hyprland Component Summary:\n")
+	for _, c := range catalogue {
+		log.WriteString(fmt.Sprintf("- %s checked.\n", c.Name))
 	}
-	fmt.Println(buf.String())
 }
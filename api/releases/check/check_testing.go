@@ -1,10 +1,11 @@
 package check
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
 	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
 )
 
@@ -16,6 +17,7 @@ type TestingStatus struct {
 }
 
 // CheckTestingStatus : GH releases varsa oradan alır, yoksa git branch'e göre belirler.
+// repoPath boşsa depo önbelleği kullanılır.
 func CheckTestingStatus(dotfileName, repoPath string) (TestingStatus, string, error) {
 	var log strings.Builder
 	status := TestingStatus{}
@@ -24,13 +26,15 @@ func CheckTestingStatus(dotfileName, repoPath string) (TestingStatus, string, er
 	if d == nil {
 		return status, "", fmt.Errorf("dotfile not found: %s", dotfileName)
 	}
+	repoPath, err := repoDir(*d, repoPath)
+	if err != nil {
+		return status, "", err
+	}
 
-	branchCmd := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD")
-	branchOut, err := branchCmd.Output()
+	branch, err := gitbackend.Default().CurrentBranch(context.Background(), repoPath)
 	if err != nil {
 		return status, "", fmt.Errorf("cannot detect branch: %v", err)
 	}
-	branch := strings.TrimSpace(string(branchOut))
 	status.Branch = branch
 
 	log.WriteString(fmt.Sprintf("🔎 Detected branch: %s\n", branch))
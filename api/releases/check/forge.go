@@ -0,0 +1,319 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/config"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+// Forge kinds
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	// ForgeGitea : Gitea and its forks (Forgejo, Codeberg)
+	ForgeGitea = "gitea"
+	// ForgeGit : any git remote (sourcehut, cgit, self-hosted); tags only
+	ForgeGit = "git"
+)
+
+// ErrNoReleases : the forge has no release concept; use tags instead
+var ErrNoReleases = errors.New("forge has no releases")
+
+// ForgeRepo : repository metadata
+type ForgeRepo struct {
+	// Path : owner/name, or group/subgroup/name on GitLab
+	Path          string    `json:"path"`
+	DefaultBranch string    `json:"default_branch"`
+	Archived      bool      `json:"archived"`
+	PushedAt      time.Time `json:"pushed_at"`
+}
+
+// ForgeTag : a tag and the commit it points to
+type ForgeTag struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
+// ForgeCommit : a commit on a branch
+type ForgeCommit struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+}
+
+// Forge : release and tag lookups for one repository on its hosting
+// service. Lists are newest first; missing repositories and refs are
+// ErrNotFound.
+type Forge interface {
+	// Kind : ForgeGitHub, ForgeGitLab, ForgeGitea or ForgeGit
+	Kind() string
+	// Repo : repository metadata
+	Repo(ctx context.Context) (*ForgeRepo, error)
+	// Releases : published releases (no drafts), or ErrNoReleases
+	Releases(ctx context.Context, limit int) ([]Release, error)
+	// Tags : tags with the commit they point to
+	Tags(ctx context.Context, limit int) ([]ForgeTag, error)
+	// LatestCommit : newest commit on ref (a branch, tag or SHA); empty
+	// ref means the default branch
+	LatestCommit(ctx context.Context, ref string) (*ForgeCommit, error)
+	// RateLimit : API quota as of the last response; zero when unknown
+	RateLimit() RateLimit
+}
+
+// ForgeName : display name of a forge kind
+func ForgeName(kind string) string {
+	switch kind {
+	case ForgeGitHub:
+		return "GitHub"
+	case ForgeGitLab:
+		return "GitLab"
+	case ForgeGitea:
+		return "Gitea"
+	}
+	return "git"
+}
+
+// ForgeKind : forge kind of a registry forge value (see
+// summaryofversion.Forges); empty stays empty
+func ForgeKind(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return "", nil
+	case ForgeGitHub:
+		return ForgeGitHub, nil
+	case ForgeGitLab:
+		return ForgeGitLab, nil
+	case ForgeGitea, "forgejo", "codeberg":
+		return ForgeGitea, nil
+	case ForgeGit, "sourcehut", "srht":
+		return ForgeGit, nil
+	}
+	return "", fmt.Errorf("unknown forge %q", name)
+}
+
+// ForgeFor : forge of a repository given as a clone URL or GitHub
+// owner/name. kind (a registry forge value) wins; otherwise a [[forge]]
+// config entry for the host, then the host name decides: github.com,
+// gitlab.com and gitlab.*, codeberg.org, gitea.* and forgejo.* are
+// recognized, anything else (git.sr.ht included) is plain git.
+func ForgeFor(repo, kind string) (Forge, error) {
+	kind, err := ForgeKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	loc, ok := parseRepoURL(repo)
+	if !ok {
+		// local paths, file:// and other remotes git understands
+		if kind != "" && kind != ForgeGit {
+			return nil, fmt.Errorf("%s is not a %s repository URL", repo, ForgeName(kind))
+		}
+		return NewGitForge(repo), nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "⚠️ config:", err)
+	}
+	fc, _ := cfg.Forge(loc.hostname())
+	fcKind, err := ForgeKind(fc.Type)
+	if err != nil {
+		return nil, fmt.Errorf("config: [[forge]] %s: %v", fc.Host, err)
+	}
+	switch {
+	case kind == "":
+		kind = fcKind
+	case fcKind != "" && fcKind != kind:
+		// the entry's token and API URL are for another kind of forge
+		fc = config.Forge{}
+	}
+	if kind == "" {
+		kind = detectForge(loc.hostname())
+	}
+
+	switch kind {
+	case ForgeGitHub:
+		owner, name, ok := loc.ownerName()
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a github.com/owner/name URL", repo)
+		}
+		gh := DefaultGitHubClient()
+		if fc.Token != "" || fc.APIURL != "" || !gh.isGitHubHost(loc.hostname()) {
+			base := fc.APIURL
+			if base == "" {
+				base = loc.scheme + "://" + loc.host + "/api/v3"
+			}
+			gh = sharedClient(ForgeGitHub, base, fc.Token, func(dir string) *GitHubClient {
+				return &GitHubClient{BaseURL: base, Token: fc.Token, CacheDir: dir}
+			})
+		}
+		return gh.Forge(owner, name), nil
+	case ForgeGitLab:
+		base := fc.APIURL
+		if base == "" {
+			base = loc.scheme + "://" + loc.host + "/api/v4"
+		}
+		gl := sharedClient(ForgeGitLab, base, fc.Token, func(dir string) *GitLabClient {
+			return &GitLabClient{BaseURL: base, Token: fc.Token, CacheDir: dir}
+		})
+		return gl.Forge(loc.path), nil
+	case ForgeGitea:
+		owner, name, ok := loc.ownerName()
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a host/owner/name URL", repo)
+		}
+		base := fc.APIURL
+		if base == "" {
+			base = loc.scheme + "://" + loc.host + "/api/v1"
+		}
+		gt := sharedClient(ForgeGitea, base, fc.Token, func(dir string) *GiteaClient {
+			return &GiteaClient{BaseURL: base, Token: fc.Token, CacheDir: dir}
+		})
+		return gt.Forge(owner, name), nil
+	}
+	return NewGitForge(gitURL(repo)), nil
+}
+
+// gitURL : clone URL of a repository given as in ForgeFor
+func gitURL(repo string) string {
+	if loc, ok := parseRepoURL(repo); ok && loc.shorthand {
+		return "https://" + loc.host + "/" + loc.path + ".git"
+	}
+	return repo
+}
+
+// LatestVersion : tag of the newest release, or the newest tag when the
+// repository has no releases; empty when it has neither
+func LatestVersion(ctx context.Context, f Forge) (string, error) {
+	releases, err := f.Releases(ctx, 1)
+	if err != nil && !errors.Is(err, ErrNoReleases) {
+		return "", err
+	}
+	if len(releases) > 0 {
+		return releases[0].Tag, nil
+	}
+	tags, err := f.Tags(ctx, 1)
+	if err != nil || len(tags) == 0 {
+		return "", err
+	}
+	return tags[0].Name, nil
+}
+
+// sortTags : newest version first; tags that are not versions come after
+// all that are, in reverse name order
+func sortTags(tags []ForgeTag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, erri := version.Parse(tags[i].Name)
+		vj, errj := version.Parse(tags[j].Name)
+		switch {
+		case erri == nil && errj == nil:
+			if c := vi.Compare(vj); c != 0 {
+				return c > 0
+			}
+		case (erri == nil) != (errj == nil):
+			return erri == nil
+		}
+		return tags[i].Name > tags[j].Name
+	})
+}
+
+// detectForge : forge kind from a host name
+func detectForge(host string) string {
+	host = strings.ToLower(host)
+	first, _, _ := strings.Cut(host, ".")
+	switch {
+	case host == "github.com" || host == "www.github.com":
+		return ForgeGitHub
+	case host == "gitlab.com" || first == "gitlab":
+		return ForgeGitLab
+	case host == "codeberg.org" || first == "gitea" || first == "forgejo":
+		return ForgeGitea
+	}
+	if DefaultGitHubClient().isGitHubHost(host) {
+		return ForgeGitHub
+	}
+	return ForgeGit
+}
+
+// repoURL : a repository URL taken apart
+type repoURL struct {
+	// scheme : http or https; ssh remotes use https for the API
+	scheme string
+	// host : host[:port] of the web/API server
+	host string
+	// path : repository path without leading slash and .git
+	path string
+	// shorthand : given as owner/name
+	shorthand bool
+}
+
+func (r repoURL) hostname() string {
+	if h, _, ok := strings.Cut(r.host, ":"); ok {
+		return h
+	}
+	return r.host
+}
+
+func (r repoURL) ownerName() (string, string, bool) {
+	parts := strings.Split(r.path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// parseRepoURL : accepts http(s), ssh and git:// URLs, scp-like
+// user@host:path and owner/name (on github.com)
+func parseRepoURL(repo string) (repoURL, bool) {
+	var r repoURL
+	if u, err := url.Parse(repo); err == nil && u.Host != "" {
+		switch u.Scheme {
+		case "http", "https":
+			r.scheme, r.host = u.Scheme, u.Host
+		case "ssh", "git", "git+ssh":
+			// the ssh port says nothing about the web server
+			r.scheme, r.host = "https", u.Hostname()
+		default:
+			return r, false
+		}
+		r.path = u.Path
+	} else if at, rest, found := strings.Cut(repo, ":"); found && strings.Contains(at, "@") && !strings.Contains(at, "/") {
+		r.scheme, r.host, r.path = "https", at[strings.Index(at, "@")+1:], rest
+	} else if strings.Count(repo, "/") == 1 && !strings.ContainsAny(repo, ":\\") && !strings.HasPrefix(repo, ".") {
+		r.scheme, r.host, r.path, r.shorthand = "https", "github.com", repo, true
+	} else {
+		return r, false
+	}
+	r.path = strings.TrimSuffix(strings.Trim(r.path, "/"), ".git")
+	return r, r.path != "" && r.host != ""
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[string]any)
+)
+
+// sharedClient : one client per forge kind, API root and token, so the ETag
+// cache and rate limit state are shared by every repository on the host
+func sharedClient[T any](kind, base, token string, create func(cacheDir string) *T) *T {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	key := kind + "\x00" + base + "\x00" + token
+	if c, ok := clients[key].(*T); ok {
+		return c
+	}
+	c := create(filepath.Join(paths.CacheDir(), kind))
+	clients[key] = c
+	return c
+}
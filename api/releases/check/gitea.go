@@ -0,0 +1,175 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GiteaClient : minimal Gitea REST (v1) client; Forgejo and Codeberg serve
+// the same API. Same ETag cache and rate limit handling as GitHubClient.
+type GiteaClient struct {
+	// BaseURL : REST API root, e.g. https://codeberg.org/api/v1
+	BaseURL string
+	// Token : optional access token
+	Token string
+	// HTTP : client used for requests; nil means a 30 second timeout
+	HTTP *http.Client
+	// CacheDir : where ETag responses are kept; empty keeps them in memory
+	CacheDir string
+
+	cache restCache
+}
+
+// Releases : newest releases first, drafts excluded
+func (c *GiteaClient) Releases(ctx context.Context, owner, name string, limit int) ([]Release, error) {
+	var raw []struct {
+		TagName     string `json:"tag_name"`
+		Name        string `json:"name"`
+		Body        string `json:"body"`
+		Draft       bool   `json:"draft"`
+		PublishedAt string `json:"published_at"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/releases?draft=false&limit=%d", owner, name, perPage(limit)), &raw); err != nil {
+		return nil, err
+	}
+	var releases []Release
+	for _, r := range raw {
+		if r.Draft {
+			continue
+		}
+		releases = append(releases, Release{Tag: r.TagName, Name: r.Name, Body: r.Body, PublishedAt: r.PublishedAt})
+		if limit > 0 && len(releases) == limit {
+			break
+		}
+	}
+	return releases, nil
+}
+
+// Tags : tags, newest first
+func (c *GiteaClient) Tags(ctx context.Context, owner, name string, limit int) ([]ForgeTag, error) {
+	var raw []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/tags?limit=%d", owner, name, perPage(limit)), &raw); err != nil {
+		return nil, err
+	}
+	tags := make([]ForgeTag, 0, len(raw))
+	for _, t := range raw {
+		tags = append(tags, ForgeTag{Name: t.Name, Commit: t.Commit.SHA})
+		if limit > 0 && len(tags) == limit {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// Repo : repository metadata
+func (c *GiteaClient) Repo(ctx context.Context, owner, name string) (*ForgeRepo, error) {
+	var raw struct {
+		FullName      string    `json:"full_name"`
+		DefaultBranch string    `json:"default_branch"`
+		Archived      bool      `json:"archived"`
+		UpdatedAt     time.Time `json:"updated_at"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s", owner, name), &raw); err != nil {
+		return nil, err
+	}
+	return &ForgeRepo{Path: raw.FullName, DefaultBranch: raw.DefaultBranch, Archived: raw.Archived, PushedAt: raw.UpdatedAt}, nil
+}
+
+// LatestCommit : newest commit on ref (a branch, tag or SHA); empty ref
+// means the default branch
+func (c *GiteaClient) LatestCommit(ctx context.Context, owner, name, ref string) (*ForgeCommit, error) {
+	if ref == "" {
+		r, err := c.Repo(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		ref = r.DefaultBranch
+	}
+	var raw []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/commits?sha=%s&limit=1&stat=false&verification=false&files=false", owner, name, url.QueryEscape(ref))
+	if err := c.get(ctx, path, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s: %w on Gitea", ref, ErrNotFound)
+	}
+	return &ForgeCommit{
+		SHA:     raw[0].SHA,
+		Message: strings.SplitN(raw[0].Commit.Message, "\n", 2)[0],
+		Author:  raw[0].Commit.Author.Name,
+		Date:    raw[0].Commit.Committer.Date,
+	}, nil
+}
+
+// RateLimit : quota reported by the last response (zero before any
+// request, and on instances that do not report one)
+func (c *GiteaClient) RateLimit() RateLimit {
+	return c.cache.rateLimit()
+}
+
+// Forge : the client bound to owner/name
+func (c *GiteaClient) Forge(owner, name string) Forge {
+	return &giteaForge{c: c, owner: owner, name: name}
+}
+
+type giteaForge struct {
+	c           *GiteaClient
+	owner, name string
+}
+
+func (f *giteaForge) Kind() string { return ForgeGitea }
+
+func (f *giteaForge) Repo(ctx context.Context) (*ForgeRepo, error) {
+	return f.c.Repo(ctx, f.owner, f.name)
+}
+
+func (f *giteaForge) Releases(ctx context.Context, limit int) ([]Release, error) {
+	return f.c.Releases(ctx, f.owner, f.name, limit)
+}
+
+func (f *giteaForge) Tags(ctx context.Context, limit int) ([]ForgeTag, error) {
+	return f.c.Tags(ctx, f.owner, f.name, limit)
+}
+
+func (f *giteaForge) LatestCommit(ctx context.Context, ref string) (*ForgeCommit, error) {
+	return f.c.LatestCommit(ctx, f.owner, f.name, ref)
+}
+
+func (f *giteaForge) RateLimit() RateLimit { return f.c.RateLimit() }
+
+// get : GET path and decode the JSON response into v
+func (c *GiteaClient) get(ctx context.Context, path string, v any) error {
+	api := restAPI{
+		name:     "Gitea",
+		base:     c.BaseURL,
+		token:    c.Token,
+		cacheDir: c.CacheDir,
+		http:     c.HTTP,
+		header:   http.Header{"Accept": {"application/json"}},
+		hint:     "add a token for this host under [[forge]] in config.toml",
+	}
+	if c.Token != "" {
+		api.header.Set("Authorization", "token "+c.Token)
+	}
+	return c.cache.get(ctx, api, path, v)
+}
@@ -0,0 +1,112 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGiteaReleases(t *testing.T) {
+	api := newFakeAPI(t)
+	const path = "/repos/o/dots/releases?draft=false&limit=6"
+	api.handle(path, `"gt1"`, `[
+		{"tag_name": "v2.0.0-rc1", "name": "RC", "draft": true},
+		{"tag_name": "v1.2.0", "name": "One two", "body": "notes", "published_at": "2024-05-01T00:00:00Z"},
+		{"tag_name": "v1.1.0", "name": "One one", "published_at": "2024-01-01T00:00:00Z"}
+	]`)
+	f := (&GiteaClient{BaseURL: api.URL, Token: "secret"}).Forge("o", "dots")
+	ctx := context.Background()
+
+	releases, err := f.Releases(ctx, 1)
+	if err != nil {
+		t.Fatalf("Releases: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Tag != "v1.2.0" || releases[0].Body != "notes" {
+		t.Errorf("Releases = %+v, want v1.2.0 (drafts skipped)", releases)
+	}
+	if got := api.last(path).Header.Get("Authorization"); got != "token secret" {
+		t.Errorf("Authorization = %q, want token secret", got)
+	}
+	if _, err := f.Releases(ctx, 1); err != nil {
+		t.Fatalf("revalidated Releases: %v", err)
+	}
+	if got := api.last(path).Header.Get("If-None-Match"); got != `"gt1"` {
+		t.Errorf("If-None-Match = %q", got)
+	}
+	if got, err := LatestVersion(ctx, f); err != nil || got != "v1.2.0" {
+		t.Errorf("LatestVersion = %q, %v; want v1.2.0", got, err)
+	}
+}
+
+func TestGiteaRepo(t *testing.T) {
+	api := newFakeAPI(t)
+	api.handle("/repos/o/dots", "", `{
+		"full_name": "o/dots", "default_branch": "main",
+		"archived": false, "updated_at": "2024-03-01T10:00:00Z"
+	}`)
+	api.handle("/repos/o/dots/commits?sha=main&limit=1&stat=false&verification=false&files=false", "", `[{
+		"sha": "abc123",
+		"commit": {
+			"message": "Fix waybar\n\nLonger description",
+			"author": {"name": "Alice"},
+			"committer": {"date": "2024-03-01T09:00:00Z"}
+		}
+	}]`)
+	api.handle("/repos/o/dots/commits?sha=gone&limit=1&stat=false&verification=false&files=false", "", `[]`)
+	api.handle("/repos/o/dots/releases?draft=false&limit=6", "", `[]`)
+	api.handle("/repos/o/dots/tags?limit=6", "", `[
+		{"name": "v0.2", "commit": {"sha": "c2"}},
+		{"name": "v0.1", "commit": {"sha": "c1"}}
+	]`)
+	f := (&GiteaClient{BaseURL: api.URL}).Forge("o", "dots")
+	ctx := context.Background()
+
+	repo, err := f.Repo(ctx)
+	if err != nil {
+		t.Fatalf("Repo: %v", err)
+	}
+	if repo.Path != "o/dots" || repo.DefaultBranch != "main" || repo.Archived || !repo.PushedAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Repo = %+v", repo)
+	}
+	c, err := f.LatestCommit(ctx, "")
+	if err != nil {
+		t.Fatalf("LatestCommit: %v", err)
+	}
+	// only the subject line of the message is kept
+	if c.SHA != "abc123" || c.Message != "Fix waybar" || c.Author != "Alice" || !c.Date.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("LatestCommit = %+v", c)
+	}
+	if _, err := f.LatestCommit(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("empty commit list: %v, want ErrNotFound", err)
+	}
+	// no releases: the newest tag
+	if got, err := LatestVersion(ctx, f); err != nil || got != "v0.2" {
+		t.Errorf("LatestVersion = %q, %v; want v0.2", got, err)
+	}
+}
+
+func TestGiteaErrors(t *testing.T) {
+	api := newFakeAPI(t)
+	api.route("/repos/o/private", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"token does not have required scope"}`))
+	})
+	c := &GiteaClient{BaseURL: api.URL}
+	ctx := context.Background()
+
+	if _, err := c.Repo(ctx, "o", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing repo: %v, want ErrNotFound", err)
+	}
+	// a 403 without rate limit headers is a permission error
+	_, err := c.Repo(ctx, "o", "private")
+	var rl *RateLimitError
+	if err == nil || errors.As(err, &rl) || err.Error() != "Gitea API /repos/o/private: token does not have required scope" {
+		t.Errorf("forbidden repo: %v", err)
+	}
+	// instances without rate limit headers report no quota
+	if l := c.RateLimit(); l != (RateLimit{}) {
+		t.Errorf("RateLimit = %+v, want zero", l)
+	}
+}
@@ -0,0 +1,111 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+)
+
+// NewGitForge : forge for any git remote (sourcehut, cgit, a local path).
+// It has no releases and no commit metadata beyond hashes; tags come from
+// ls-remote, newest version first.
+func NewGitForge(url string) Forge {
+	return &gitForge{url: url}
+}
+
+type gitForge struct {
+	url string
+
+	once sync.Once
+	refs []gitbackend.Ref
+	err  error
+}
+
+func (f *gitForge) Kind() string { return ForgeGit }
+
+// lsRemote : refs of the remote, listed once per forge
+func (f *gitForge) lsRemote(ctx context.Context) ([]gitbackend.Ref, error) {
+	f.once.Do(func() {
+		f.refs, f.err = gitbackend.Default().LsRemote(ctx, f.url)
+	})
+	return f.refs, f.err
+}
+
+func (f *gitForge) Repo(ctx context.Context) (*ForgeRepo, error) {
+	refs, err := f.lsRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := &ForgeRepo{Path: f.url}
+	for _, ref := range refs {
+		if ref.Name == "HEAD" {
+			r.DefaultBranch = strings.TrimPrefix(ref.Target, "refs/heads/")
+		}
+	}
+	return r, nil
+}
+
+func (f *gitForge) Releases(ctx context.Context, limit int) ([]Release, error) {
+	return nil, ErrNoReleases
+}
+
+func (f *gitForge) Tags(ctx context.Context, limit int) ([]ForgeTag, error) {
+	refs, err := f.lsRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var tags []ForgeTag
+	index := make(map[string]int)
+	for _, ref := range refs {
+		name, ok := strings.CutPrefix(ref.Name, "refs/tags/")
+		if !ok {
+			continue
+		}
+		if tag, peeled := strings.CutSuffix(name, "^{}"); peeled {
+			// annotated tag: the peeled entry has the commit
+			if i, ok := index[tag]; ok {
+				tags[i].Commit = ref.Hash
+			}
+			continue
+		}
+		if _, seen := index[name]; !seen {
+			index[name] = len(tags)
+			tags = append(tags, ForgeTag{Name: name, Commit: ref.Hash})
+		}
+	}
+	// ls-remote sorts by name; order by version instead
+	sortTags(tags)
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+func (f *gitForge) LatestCommit(ctx context.Context, ref string) (*ForgeCommit, error) {
+	refs, err := f.lsRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	want := []string{"refs/heads/" + ref, "refs/tags/" + ref + "^{}", "refs/tags/" + ref}
+	if ref == "" {
+		want = []string{"HEAD"}
+	}
+	for _, name := range want {
+		for _, r := range refs {
+			if r.Name == name && r.Hash != "" {
+				return &ForgeCommit{SHA: r.Hash}, nil
+			}
+		}
+	}
+	for _, r := range refs {
+		if ref != "" && strings.HasPrefix(r.Hash, ref) {
+			return &ForgeCommit{SHA: r.Hash}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w on %s", ref, ErrNotFound, f.url)
+}
+
+func (f *gitForge) RateLimit() RateLimit { return RateLimit{} }
@@ -0,0 +1,104 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+)
+
+// tagRepo : bare repository with three commits on trunk. v0.2 (annotated)
+// and nightly (lightweight) are on the first, v0.9 (lightweight) on the
+// second and v0.10.0 (annotated) on the third. Returns its URL and the
+// commit hashes, oldest first.
+func tagRepo(t *testing.T) (string, []string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	work, bare := t.TempDir(), filepath.Join(t.TempDir(), "repo.git")
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git(work, "init", "-q", "-b", "trunk")
+	var commits []string
+	for i, tags := range [][]string{{"-a v0.2", "nightly"}, {"v0.9"}, {"-a v0.10.0"}} {
+		git(work, "commit", "-q", "--allow-empty", "-m", "commit "+string(rune('1'+i)))
+		commits = append(commits, git(work, "rev-parse", "HEAD"))
+		for _, tag := range tags {
+			if name, annotated := strings.CutPrefix(tag, "-a "); annotated {
+				git(work, "tag", "-a", name, "-m", "release "+name)
+			} else {
+				git(work, "tag", tag)
+			}
+		}
+	}
+	git(work, "clone", "-q", "--bare", work, bare)
+	return "file://" + bare, commits
+}
+
+func TestGitForge(t *testing.T) {
+	url, commits := tagRepo(t)
+	ctx := context.Background()
+
+	for _, backend := range []gitbackend.Backend{gitbackend.Exec{}, gitbackend.Native{}} {
+		t.Run(backend.Name(), func(t *testing.T) {
+			gitbackend.SetDefault(backend)
+			defer gitbackend.SetDefault(nil)
+			f := NewGitForge(url)
+
+			// ls-remote lists nightly, v0.10.0, v0.10.0^{}, v0.2, v0.2^{},
+			// v0.9; tags are ordered by version and annotated tags carry
+			// the commit of their peeled entry
+			tags, err := f.Tags(ctx, 0)
+			if err != nil {
+				t.Fatalf("Tags: %v", err)
+			}
+			var got []string
+			for _, tag := range tags {
+				got = append(got, tag.Name)
+			}
+			if want := "v0.10.0 v0.9 v0.2 nightly"; strings.Join(got, " ") != want {
+				t.Errorf("Tags = %s, want %s", strings.Join(got, " "), want)
+			}
+			for i, want := range []string{commits[2], commits[1], commits[0], commits[0]} {
+				if i < len(tags) && tags[i].Commit != want {
+					t.Errorf("%s commit = %s, want %s", tags[i].Name, tags[i].Commit, want)
+				}
+			}
+			if tags, _ := f.Tags(ctx, 1); len(tags) != 1 || tags[0].Name != "v0.10.0" {
+				t.Errorf("Tags(limit 1) = %+v", tags)
+			}
+
+			if v, err := LatestVersion(ctx, f); err != nil || v != "v0.10.0" {
+				t.Errorf("LatestVersion = %q, %v; want v0.10.0", v, err)
+			}
+			if r, err := f.Repo(ctx); err != nil || r.DefaultBranch != "trunk" {
+				t.Errorf("Repo = %+v, %v; want default branch trunk", r, err)
+			}
+			for ref, want := range map[string]string{"": commits[2], "trunk": commits[2], "v0.2": commits[0], "v0.9": commits[1], commits[1][:10]: commits[1]} {
+				if c, err := f.LatestCommit(ctx, ref); err != nil || c.SHA != want {
+					t.Errorf("LatestCommit(%q) = %+v, %v; want %s", ref, c, err, want)
+				}
+			}
+			if _, err := f.LatestCommit(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("LatestCommit(gone) = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/config"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// GitHubClient : minimal GitHub REST client. Responses are cached with
+// their ETag and revalidated with If-None-Match, which GitHub does not
+// count against the rate limit. While the quota is used up, cached
+// responses are served and uncached requests fail with RateLimitError.
+// Forge binds it to one repository.
+type GitHubClient struct {
+	// BaseURL : REST API root, e.g. https://api.github.com
+	BaseURL string
+	// Token : optional; sent as a bearer token
+	Token string
+	// HTTP : client used for requests; nil means a 30 second timeout
+	HTTP *http.Client
+	// CacheDir : where ETag responses are kept; empty keeps them in memory
+	CacheDir string
+
+	cache restCache
+}
+
+var (
+	gitHubMu      sync.Mutex
+	gitHubDefault *GitHubClient
+)
+
+// NewGitHubClient : client configured from config.toml and the environment,
+// caching responses under $XDG_CACHE_HOME/hypr-release/github
+func NewGitHubClient() *GitHubClient {
+	c, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "⚠️ config:", err)
+	}
+	return &GitHubClient{
+		BaseURL:  c.GitHub.APIURL,
+		Token:    c.GitHub.Token,
+		CacheDir: filepath.Join(paths.CacheDir(), "github"),
+	}
+}
+
+// DefaultGitHubClient : shared client, created on first use
+func DefaultGitHubClient() *GitHubClient {
+	gitHubMu.Lock()
+	defer gitHubMu.Unlock()
+	if gitHubDefault == nil {
+		gitHubDefault = NewGitHubClient()
+	}
+	return gitHubDefault
+}
+
+// SetGitHubClient : replaces the shared client (nil re-reads config)
+func SetGitHubClient(c *GitHubClient) {
+	gitHubMu.Lock()
+	defer gitHubMu.Unlock()
+	gitHubDefault = c
+}
+
+// isGitHubHost : github.com, or the Enterprise host of BaseURL
+func (c *GitHubClient) isGitHubHost(host string) bool {
+	if host == "github.com" || host == "www.github.com" {
+		return true
+	}
+	if u, err := url.Parse(c.BaseURL); err == nil && u.Hostname() != "api.github.com" {
+		return strings.EqualFold(host, u.Hostname())
+	}
+	return false
+}
+
+// Releases : newest releases first, drafts excluded
+func (c *GitHubClient) Releases(ctx context.Context, owner, name string, limit int) ([]Release, error) {
+	var raw []struct {
+		TagName     string `json:"tag_name"`
+		Name        string `json:"name"`
+		Body        string `json:"body"`
+		Draft       bool   `json:"draft"`
+		PublishedAt string `json:"published_at"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/releases?per_page=%d", owner, name, perPage(limit)), &raw); err != nil {
+		return nil, err
+	}
+	var releases []Release
+	for _, r := range raw {
+		if r.Draft {
+			continue
+		}
+		releases = append(releases, Release{Tag: r.TagName, Name: r.Name, Body: r.Body, PublishedAt: r.PublishedAt})
+		if limit > 0 && len(releases) == limit {
+			break
+		}
+	}
+	return releases, nil
+}
+
+// Tags : tags, newest version first. GitHub lists tags by name, not by
+// age, so a full page is fetched and sorted before limit is applied.
+func (c *GitHubClient) Tags(ctx context.Context, owner, name string, limit int) ([]ForgeTag, error) {
+	var raw []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/tags?per_page=100", owner, name), &raw); err != nil {
+		return nil, err
+	}
+	tags := make([]ForgeTag, 0, len(raw))
+	for _, t := range raw {
+		tags = append(tags, ForgeTag{Name: t.Name, Commit: t.Commit.SHA})
+	}
+	sortTags(tags)
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+// Repo : repository metadata
+func (c *GitHubClient) Repo(ctx context.Context, owner, name string) (*ForgeRepo, error) {
+	var raw struct {
+		FullName      string    `json:"full_name"`
+		DefaultBranch string    `json:"default_branch"`
+		Archived      bool      `json:"archived"`
+		PushedAt      time.Time `json:"pushed_at"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s", owner, name), &raw); err != nil {
+		return nil, err
+	}
+	return &ForgeRepo{Path: raw.FullName, DefaultBranch: raw.DefaultBranch, Archived: raw.Archived, PushedAt: raw.PushedAt}, nil
+}
+
+// DefaultBranch : the repository's default branch
+func (c *GitHubClient) DefaultBranch(ctx context.Context, owner, name string) (string, error) {
+	r, err := c.Repo(ctx, owner, name)
+	if err != nil {
+		return "", err
+	}
+	return r.DefaultBranch, nil
+}
+
+// LatestCommit : newest commit on ref (a branch, tag or SHA); empty ref
+// means the default branch
+func (c *GitHubClient) LatestCommit(ctx context.Context, owner, name, ref string) (*ForgeCommit, error) {
+	if ref == "" {
+		var err error
+		if ref, err = c.DefaultBranch(ctx, owner, name); err != nil {
+			return nil, err
+		}
+	}
+	var raw struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string    `json:"name"`
+				Date time.Time `json:"date"`
+			} `json:"author"`
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/commits/%s", owner, name, url.PathEscape(ref)), &raw); err != nil {
+		return nil, err
+	}
+	return &ForgeCommit{
+		SHA:     raw.SHA,
+		Message: strings.SplitN(raw.Commit.Message, "\n", 2)[0],
+		Author:  raw.Commit.Author.Name,
+		Date:    raw.Commit.Committer.Date,
+	}, nil
+}
+
+// Forge : the client bound to owner/name
+func (c *GitHubClient) Forge(owner, name string) Forge {
+	return &githubForge{c: c, owner: owner, name: name}
+}
+
+type githubForge struct {
+	c           *GitHubClient
+	owner, name string
+}
+
+func (f *githubForge) Kind() string { return ForgeGitHub }
+
+func (f *githubForge) Repo(ctx context.Context) (*ForgeRepo, error) {
+	return f.c.Repo(ctx, f.owner, f.name)
+}
+
+func (f *githubForge) Releases(ctx context.Context, limit int) ([]Release, error) {
+	return f.c.Releases(ctx, f.owner, f.name, limit)
+}
+
+func (f *githubForge) Tags(ctx context.Context, limit int) ([]ForgeTag, error) {
+	return f.c.Tags(ctx, f.owner, f.name, limit)
+}
+
+func (f *githubForge) LatestCommit(ctx context.Context, ref string) (*ForgeCommit, error) {
+	return f.c.LatestCommit(ctx, f.owner, f.name, ref)
+}
+
+func (f *githubForge) RateLimit() RateLimit { return f.c.RateLimit() }
+
+// RateLimit : quota reported by the last response (zero before any request)
+func (c *GitHubClient) RateLimit() RateLimit {
+	return c.cache.rateLimit()
+}
+
+// get : GET path and decode the JSON response into v
+func (c *GitHubClient) get(ctx context.Context, path string, v any) error {
+	api := restAPI{
+		name:     "GitHub",
+		base:     c.BaseURL,
+		token:    c.Token,
+		cacheDir: c.CacheDir,
+		http:     c.HTTP,
+		header: http.Header{
+			"Accept":               {"application/vnd.github+json"},
+			"X-Github-Api-Version": {"2022-11-28"},
+		},
+		hint: fmt.Sprintf("set %s to raise it", config.GitHubTokenEnv),
+	}
+	if c.Token != "" {
+		api.header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return c.cache.get(ctx, api, path, v)
+}
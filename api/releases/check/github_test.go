@@ -0,0 +1,316 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/config"
+)
+
+// fakeAPI : REST API stand-in. Routes map a request path (with query) to
+// a handler; every request is recorded.
+type fakeAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc
+	requests []*http.Request
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	f := &fakeAPI{routes: make(map[string]http.HandlerFunc)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r)
+		h, ok := f.routes[r.URL.RequestURI()]
+		f.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// handle : serves body as JSON on path; etag, when set, is sent and
+// answered with 304 when the request revalidates it
+func (f *fakeAPI) handle(path, etag, body string) {
+	f.route(path, func(w http.ResponseWriter, r *http.Request) {
+		if etag != "" {
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+func (f *fakeAPI) route(path string, h http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[path] = h
+}
+
+// count : requests made to path
+func (f *fakeAPI) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.requests {
+		if r.URL.RequestURI() == path {
+			n++
+		}
+	}
+	return n
+}
+
+// last : the last request made to path
+func (f *fakeAPI) last(path string) *http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		if f.requests[i].URL.RequestURI() == path {
+			return f.requests[i]
+		}
+	}
+	return nil
+}
+
+const ghReleases = `[
+	{"tag_name": "v0.3.0-rc1", "name": "RC", "draft": true},
+	{"tag_name": "v0.2.0", "name": "Two", "body": "notes", "published_at": "2024-05-01T00:00:00Z"},
+	{"tag_name": "v0.1.0", "name": "One", "published_at": "2024-01-01T00:00:00Z"}
+]`
+
+func TestGitHubReleases(t *testing.T) {
+	api := newFakeAPI(t)
+	api.handle("/repos/o/r/releases?per_page=6", "", ghReleases)
+	c := &GitHubClient{BaseURL: api.URL, Token: "secret"}
+
+	releases, err := c.Releases(context.Background(), "o", "r", 1)
+	if err != nil {
+		t.Fatalf("Releases: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Tag != "v0.2.0" || releases[0].Body != "notes" {
+		t.Errorf("Releases = %+v, want v0.2.0 (drafts skipped)", releases)
+	}
+	r := api.last("/repos/o/r/releases?per_page=6")
+	if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := r.Header.Get("Accept"); got != "application/vnd.github+json" {
+		t.Errorf("Accept = %q", got)
+	}
+}
+
+func TestGitHubETag(t *testing.T) {
+	api := newFakeAPI(t)
+	const path = "/repos/o/r/releases?per_page=6"
+	api.handle(path, `W/"v1"`, ghReleases)
+	cacheDir := t.TempDir()
+	ctx := context.Background()
+
+	c := &GitHubClient{BaseURL: api.URL, CacheDir: cacheDir}
+	if _, err := c.Releases(ctx, "o", "r", 1); err != nil {
+		t.Fatalf("first Releases: %v", err)
+	}
+	if got := api.last(path).Header.Get("If-None-Match"); got != "" {
+		t.Errorf("first request sent If-None-Match %q", got)
+	}
+
+	// the second request revalidates and the 304 is answered from cache
+	releases, err := c.Releases(ctx, "o", "r", 1)
+	if err != nil {
+		t.Fatalf("revalidated Releases: %v", err)
+	}
+	if got := api.last(path).Header.Get("If-None-Match"); got != `W/"v1"` {
+		t.Errorf("If-None-Match = %q, want the cached ETag", got)
+	}
+	if len(releases) != 1 || releases[0].Tag != "v0.2.0" {
+		t.Errorf("Releases from 304 = %+v", releases)
+	}
+
+	// a new client finds the ETag in CacheDir
+	c2 := &GitHubClient{BaseURL: api.URL, CacheDir: cacheDir}
+	if _, err := c2.Releases(ctx, "o", "r", 1); err != nil {
+		t.Fatalf("Releases with disk cache: %v", err)
+	}
+	if got := api.last(path).Header.Get("If-None-Match"); got != `W/"v1"` {
+		t.Errorf("If-None-Match from disk cache = %q", got)
+	}
+
+	// responses cached anonymously are not reused with a token
+	c3 := &GitHubClient{BaseURL: api.URL, CacheDir: cacheDir, Token: "secret"}
+	if _, err := c3.Releases(ctx, "o", "r", 1); err != nil {
+		t.Fatalf("Releases with token: %v", err)
+	}
+	if got := api.last(path).Header.Get("If-None-Match"); got != "" {
+		t.Errorf("authenticated request reused the anonymous ETag %q", got)
+	}
+	if n := api.count(path); n != 4 {
+		t.Errorf("%d requests, want 4", n)
+	}
+}
+
+func TestGitHubRateLimit(t *testing.T) {
+	api := newFakeAPI(t)
+	ctx := context.Background()
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	limited := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+	}
+
+	cached := "/repos/o/r/releases?per_page=6"
+	api.route(cached, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"r1"`)
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "1")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Write([]byte(ghReleases))
+	})
+	api.route("/repos/o/r/tags?per_page=100", limited)
+
+	c := &GitHubClient{BaseURL: api.URL}
+	if _, err := c.Releases(ctx, "o", "r", 1); err != nil {
+		t.Fatalf("Releases: %v", err)
+	}
+	if l := c.RateLimit(); l.Limit != 60 || l.Remaining != 1 || !l.Reset.Equal(reset) {
+		t.Errorf("RateLimit = %+v", l)
+	}
+
+	// an uncached request hitting the limit fails with RateLimitError
+	_, err := c.Tags(ctx, "o", "r", 1)
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("Tags error = %v, want RateLimitError", err)
+	}
+	if rl.API != "GitHub" || rl.Authenticated || !rl.Reset.Equal(reset) || !strings.Contains(rl.Error(), config.GitHubTokenEnv) {
+		t.Errorf("RateLimitError = %+v (%v)", rl, rl)
+	}
+
+	// while the quota is used up, cached responses are served without a
+	// request and uncached ones fail without one
+	api.route(cached, limited)
+	before := api.count(cached)
+	releases, err := c.Releases(ctx, "o", "r", 1)
+	if err != nil || len(releases) != 1 {
+		t.Errorf("cached Releases while limited = %+v, %v", releases, err)
+	}
+	if api.count(cached) != before {
+		t.Errorf("request made while the quota is used up")
+	}
+	if _, err := c.Repo(ctx, "o", "r"); !errors.As(err, &rl) {
+		t.Errorf("Repo while limited = %v, want RateLimitError", err)
+	}
+	if n := api.count("/repos/o/r"); n != 0 {
+		t.Errorf("%d requests for /repos/o/r while limited", n)
+	}
+}
+
+func TestGitHubSecondaryRateLimit(t *testing.T) {
+	api := newFakeAPI(t)
+	api.route("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c := &GitHubClient{BaseURL: api.URL, Token: "secret"}
+	_, err := c.Repo(context.Background(), "o", "r")
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("Repo error = %v, want RateLimitError", err)
+	}
+	if !rl.Authenticated || strings.Contains(rl.Error(), config.GitHubTokenEnv) {
+		t.Errorf("authenticated RateLimitError = %v", rl)
+	}
+	if d := time.Until(rl.Reset); d < 20*time.Second || d > 40*time.Second {
+		t.Errorf("Reset in %v, want about 30s", d)
+	}
+}
+
+func TestGitHubErrors(t *testing.T) {
+	api := newFakeAPI(t)
+	api.route("/repos/o/private", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	})
+	api.handle("/repos/o/broken", "", `{"full_name": `)
+	c := &GitHubClient{BaseURL: api.URL}
+	ctx := context.Background()
+
+	if _, err := c.Repo(ctx, "o", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing repo: %v, want ErrNotFound", err)
+	}
+	_, err := c.Repo(ctx, "o", "private")
+	var rl *RateLimitError
+	if err == nil || errors.As(err, &rl) || !strings.Contains(err.Error(), "Resource not accessible") {
+		t.Errorf("forbidden repo: %v", err)
+	}
+	if _, err := c.Repo(ctx, "o", "broken"); err == nil || !strings.Contains(err.Error(), "invalid GitHub response") {
+		t.Errorf("broken JSON: %v", err)
+	}
+}
+
+func TestGitHubLatestVersion(t *testing.T) {
+	// GitHub lists tags by name, newest name first
+	const tags = `[
+		{"name": "v0.9.0", "commit": {"sha": "c9"}},
+		{"name": "v0.10.1", "commit": {"sha": "c101"}},
+		{"name": "v0.10.0", "commit": {"sha": "c100"}},
+		{"name": "nightly", "commit": {"sha": "cn"}},
+		{"name": "v0.1.0", "commit": {"sha": "c1"}}
+	]`
+	tests := []struct {
+		name     string
+		releases string
+		want     string
+	}{
+		{"release", ghReleases, "v0.2.0"},
+		{"no releases", `[]`, "v0.10.1"},
+		{"only drafts", `[{"tag_name": "v1.0.0", "draft": true}]`, "v0.10.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			api.handle("/repos/o/r/releases?per_page=6", "", tt.releases)
+			api.handle("/repos/o/r/tags?per_page=100", "", tags)
+			f := (&GitHubClient{BaseURL: api.URL}).Forge("o", "r")
+
+			got, err := LatestVersion(context.Background(), f)
+			if err != nil || got != tt.want {
+				t.Errorf("LatestVersion = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	api := newFakeAPI(t)
+	api.handle("/repos/o/r/tags?per_page=100", "", tags)
+	got, err := (&GitHubClient{BaseURL: api.URL}).Tags(context.Background(), "o", "r", 0)
+	if err != nil {
+		t.Fatalf("Tags: %v", err)
+	}
+	var names []string
+	for _, tag := range got {
+		names = append(names, tag.Name+"@"+tag.Commit)
+	}
+	if want := "v0.10.1@c101 v0.10.0@c100 v0.9.0@c9 v0.1.0@c1 nightly@cn"; strings.Join(names, " ") != want {
+		t.Errorf("Tags = %s, want %s", strings.Join(names, " "), want)
+	}
+}
@@ -0,0 +1,162 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitLabClient : minimal GitLab REST (v4) client for gitlab.com and
+// self-hosted instances, with the same ETag cache and rate limit handling
+// as GitHubClient. Projects are addressed by their full path, so nested
+// groups work.
+type GitLabClient struct {
+	// BaseURL : REST API root, e.g. https://gitlab.com/api/v4
+	BaseURL string
+	// Token : optional personal or project access token
+	Token string
+	// HTTP : client used for requests; nil means a 30 second timeout
+	HTTP *http.Client
+	// CacheDir : where ETag responses are kept; empty keeps them in memory
+	CacheDir string
+
+	cache restCache
+}
+
+// Releases : newest releases first; upcoming (future-dated) releases are
+// left out like drafts on GitHub
+func (c *GitLabClient) Releases(ctx context.Context, project string, limit int) ([]Release, error) {
+	var raw []struct {
+		TagName     string `json:"tag_name"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		ReleasedAt  string `json:"released_at"`
+		Upcoming    bool   `json:"upcoming_release"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/releases?per_page=%d", url.PathEscape(project), perPage(limit)), &raw); err != nil {
+		return nil, err
+	}
+	var releases []Release
+	for _, r := range raw {
+		if r.Upcoming {
+			continue
+		}
+		releases = append(releases, Release{Tag: r.TagName, Name: r.Name, Body: r.Description, PublishedAt: r.ReleasedAt})
+		if limit > 0 && len(releases) == limit {
+			break
+		}
+	}
+	return releases, nil
+}
+
+// Tags : tags, most recently updated first
+func (c *GitLabClient) Tags(ctx context.Context, project string, limit int) ([]ForgeTag, error) {
+	var raw []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/repository/tags?per_page=%d", url.PathEscape(project), perPage(limit)), &raw); err != nil {
+		return nil, err
+	}
+	tags := make([]ForgeTag, 0, len(raw))
+	for _, t := range raw {
+		tags = append(tags, ForgeTag{Name: t.Name, Commit: t.Commit.ID})
+		if limit > 0 && len(tags) == limit {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// Repo : project metadata
+func (c *GitLabClient) Repo(ctx context.Context, project string) (*ForgeRepo, error) {
+	var raw struct {
+		Path           string    `json:"path_with_namespace"`
+		DefaultBranch  string    `json:"default_branch"`
+		Archived       bool      `json:"archived"`
+		LastActivityAt time.Time `json:"last_activity_at"`
+	}
+	if err := c.get(ctx, "/projects/"+url.PathEscape(project), &raw); err != nil {
+		return nil, err
+	}
+	return &ForgeRepo{Path: raw.Path, DefaultBranch: raw.DefaultBranch, Archived: raw.Archived, PushedAt: raw.LastActivityAt}, nil
+}
+
+// LatestCommit : newest commit on ref (a branch, tag or SHA); empty ref
+// means the default branch
+func (c *GitLabClient) LatestCommit(ctx context.Context, project, ref string) (*ForgeCommit, error) {
+	if ref == "" {
+		r, err := c.Repo(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		ref = r.DefaultBranch
+	}
+	var raw struct {
+		ID            string    `json:"id"`
+		Title         string    `json:"title"`
+		AuthorName    string    `json:"author_name"`
+		CommittedDate time.Time `json:"committed_date"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/repository/commits/%s", url.PathEscape(project), url.PathEscape(ref)), &raw); err != nil {
+		return nil, err
+	}
+	return &ForgeCommit{SHA: raw.ID, Message: raw.Title, Author: raw.AuthorName, Date: raw.CommittedDate}, nil
+}
+
+// RateLimit : quota reported by the last response (zero before any request)
+func (c *GitLabClient) RateLimit() RateLimit {
+	return c.cache.rateLimit()
+}
+
+// Forge : the client bound to a project path (group/subgroup/name)
+func (c *GitLabClient) Forge(project string) Forge {
+	return &gitlabForge{c: c, project: strings.Trim(project, "/")}
+}
+
+type gitlabForge struct {
+	c       *GitLabClient
+	project string
+}
+
+func (f *gitlabForge) Kind() string { return ForgeGitLab }
+
+func (f *gitlabForge) Repo(ctx context.Context) (*ForgeRepo, error) {
+	return f.c.Repo(ctx, f.project)
+}
+
+func (f *gitlabForge) Releases(ctx context.Context, limit int) ([]Release, error) {
+	return f.c.Releases(ctx, f.project, limit)
+}
+
+func (f *gitlabForge) Tags(ctx context.Context, limit int) ([]ForgeTag, error) {
+	return f.c.Tags(ctx, f.project, limit)
+}
+
+func (f *gitlabForge) LatestCommit(ctx context.Context, ref string) (*ForgeCommit, error) {
+	return f.c.LatestCommit(ctx, f.project, ref)
+}
+
+func (f *gitlabForge) RateLimit() RateLimit { return f.c.RateLimit() }
+
+// get : GET path and decode the JSON response into v
+func (c *GitLabClient) get(ctx context.Context, path string, v any) error {
+	api := restAPI{
+		name:     "GitLab",
+		base:     c.BaseURL,
+		token:    c.Token,
+		cacheDir: c.CacheDir,
+		http:     c.HTTP,
+		header:   http.Header{"Accept": {"application/json"}},
+		hint:     "add a token for this host under [[forge]] in config.toml",
+	}
+	if c.Token != "" {
+		api.header.Set("Private-Token", c.Token)
+	}
+	return c.cache.get(ctx, api, path, v)
+}
@@ -0,0 +1,121 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitLabReleases(t *testing.T) {
+	api := newFakeAPI(t)
+	// nested groups are sent as one escaped path segment
+	const path = "/projects/group%2Fsub%2Fdots/releases?per_page=7"
+	api.handle(path, `"gl1"`, `[
+		{"tag_name": "v3.0.0", "name": "Three", "released_at": "2099-01-01T00:00:00Z", "upcoming_release": true},
+		{"tag_name": "v2.0.0", "name": "Two", "description": "notes", "released_at": "2024-05-01T00:00:00Z"},
+		{"tag_name": "v1.0.0", "name": "One", "released_at": "2024-01-01T00:00:00Z"}
+	]`)
+	f := (&GitLabClient{BaseURL: api.URL, Token: "secret"}).Forge("/group/sub/dots/")
+	ctx := context.Background()
+
+	releases, err := f.Releases(ctx, 2)
+	if err != nil {
+		t.Fatalf("Releases: %v", err)
+	}
+	if len(releases) != 2 || releases[0].Tag != "v2.0.0" || releases[0].Body != "notes" || releases[1].Tag != "v1.0.0" {
+		t.Errorf("Releases = %+v, want v2.0.0 and v1.0.0 (upcoming skipped)", releases)
+	}
+	r := api.last(path)
+	if r == nil {
+		t.Fatalf("no request for %s", path)
+	}
+	if got := r.Header.Get("Private-Token"); got != "secret" {
+		t.Errorf("Private-Token = %q", got)
+	}
+	if got := r.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, GitLab takes the token in Private-Token", got)
+	}
+
+	// revalidated with the ETag, answered from cache
+	if _, err := f.Releases(ctx, 2); err != nil {
+		t.Fatalf("revalidated Releases: %v", err)
+	}
+	if got := api.last(path).Header.Get("If-None-Match"); got != `"gl1"` {
+		t.Errorf("If-None-Match = %q", got)
+	}
+}
+
+func TestGitLabRepo(t *testing.T) {
+	api := newFakeAPI(t)
+	api.handle("/projects/o%2Fdots", "", `{
+		"path_with_namespace": "o/dots", "default_branch": "trunk",
+		"archived": true, "last_activity_at": "2024-03-01T10:00:00Z"
+	}`)
+	api.handle("/projects/o%2Fdots/repository/commits/trunk", "", `{
+		"id": "abc123", "title": "Fix waybar", "author_name": "Alice",
+		"committed_date": "2024-03-01T09:00:00Z"
+	}`)
+	api.handle("/projects/o%2Fdots/repository/tags?per_page=100", "", `[
+		{"name": "v1.1", "commit": {"id": "c11"}},
+		{"name": "v1.0", "commit": {"id": "c10"}}
+	]`)
+	api.handle("/projects/o%2Fdots/repository/tags?per_page=6", "", `[{"name": "v1.1", "commit": {"id": "c11"}}]`)
+	api.handle("/projects/o%2Fdots/releases?per_page=6", "", `[]`)
+	f := (&GitLabClient{BaseURL: api.URL}).Forge("o/dots")
+	ctx := context.Background()
+
+	repo, err := f.Repo(ctx)
+	if err != nil {
+		t.Fatalf("Repo: %v", err)
+	}
+	if repo.Path != "o/dots" || repo.DefaultBranch != "trunk" || !repo.Archived || !repo.PushedAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Repo = %+v", repo)
+	}
+	// an empty ref is the default branch
+	c, err := f.LatestCommit(ctx, "")
+	if err != nil {
+		t.Fatalf("LatestCommit: %v", err)
+	}
+	if c.SHA != "abc123" || c.Message != "Fix waybar" || c.Author != "Alice" {
+		t.Errorf("LatestCommit = %+v", c)
+	}
+	tags, err := f.Tags(ctx, 0)
+	if err != nil || len(tags) != 2 || tags[0].Name != "v1.1" || tags[0].Commit != "c11" {
+		t.Errorf("Tags = %+v, %v", tags, err)
+	}
+	if _, err := f.LatestCommit(ctx, "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing ref: %v, want ErrNotFound", err)
+	}
+	if got, err := LatestVersion(ctx, f); err != nil || got != "v1.1" {
+		t.Errorf("LatestVersion without releases = %q, %v; want v1.1", got, err)
+	}
+}
+
+func TestGitLabRateLimit(t *testing.T) {
+	api := newFakeAPI(t)
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	// GitLab spells the headers without the X- prefix
+	api.route("/projects/o%2Fdots", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Limit", "2000")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c := &GitLabClient{BaseURL: api.URL}
+
+	_, err := c.Repo(context.Background(), "o/dots")
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("Repo error = %v, want RateLimitError", err)
+	}
+	if rl.API != "GitLab" || rl.Authenticated || !rl.Reset.Equal(reset) || !strings.Contains(rl.Error(), "[[forge]]") {
+		t.Errorf("RateLimitError = %+v (%v)", rl, rl)
+	}
+	if l := c.RateLimit(); l.Limit != 2000 || l.Remaining != 0 {
+		t.Errorf("RateLimit = %+v", l)
+	}
+}
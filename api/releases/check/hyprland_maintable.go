@@ -5,18 +5,39 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
 )
 
+const systemMetaFile = "/etc/hyprland-system-release"
+
+// metaKeyName : bileşen adlarını kabuk değişkeni adına çevirir
+var metaKeyName = strings.NewReplacer("-", "_", ".", "_", "+", "_")
+
 // WriteHyprSystemMeta : hyprland çekirdek araçlarının sürüm bilgilerini kaydeder
 func WriteHyprSystemMeta(components []HyprComponent) error {
-	file := "/etc/hyprland-system-release"
+	file := systemMetaFile
+	err := os.WriteFile(file, []byte(hyprSystemMetaContent(components)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", file, err)
+	}
+	return nil
+}
+
+// PlanHyprSystemMeta : WriteHyprSystemMeta'nın yazacaklarını plana ekler, dosyaya dokunmaz
+func PlanHyprSystemMeta(p *plan.Plan, components []HyprComponent) {
+	p.AddMetadata(systemMetaFile, plan.KeysOf(hyprSystemMetaContent(components)))
+}
+
+func hyprSystemMetaContent(components []HyprComponent) string {
 	var b strings.Builder
 
 	b.WriteString("# Hyprland System Release Metadata\n")
 	b.WriteString(fmt.Sprintf("HYPRLAND_SYSTEM_CHECK_DATE=\"%s\"\n", time.Now().Format("2006-01-02 15:04:05")))
 
 	for _, c := range components {
-		upper := strings.ToUpper(c.Name)
+		// xdg-desktop-portal-hyprland → XDG_DESKTOP_PORTAL_HYPRLAND
+		upper := metaKeyName.Replace(strings.ToUpper(c.Name))
 		b.WriteString(fmt.Sprintf("HYPRLAND_%s_VERSION=\"%s\"\n", upper, c.Version))
 		b.WriteString(fmt.Sprintf("HYPRLAND_%s_REMOTE_VERSION=\"%s\"\n", upper, c.RemoteVersion))
 		b.WriteString(fmt.Sprintf("HYPRLAND_%s_PATH=\"%s\"\n", upper, c.Path))
@@ -26,12 +47,38 @@ func WriteHyprSystemMeta(components []HyprComponent) error {
 			b.WriteString(fmt.Sprintf("HYPRLAND_%s_UPDATE=\"false\"\n", upper))
 		}
 		b.WriteString(fmt.Sprintf("HYPRLAND_%s_SOURCE=\"%s\"\n", upper, c.Source))
+		if bi := c.Build; bi != nil {
+			b.WriteString(fmt.Sprintf("HYPRLAND_%s_TAG=\"%s\"\n", upper, bi.Tag))
+			b.WriteString(fmt.Sprintf("HYPRLAND_%s_COMMIT=\"%s\"\n", upper, bi.Commit))
+			b.WriteString(fmt.Sprintf("HYPRLAND_%s_BRANCH=\"%s\"\n", upper, bi.Branch))
+			b.WriteString(fmt.Sprintf("HYPRLAND_%s_DIRTY=\"%t\"\n", upper, bi.Dirty))
+			if !bi.Date.IsZero() {
+				b.WriteString(fmt.Sprintf("HYPRLAND_%s_BUILD_DATE=\"%s\"\n", upper, bi.Date.Format("2006-01-02 15:04:05")))
+			}
+			bi.Libraries.each(func(lib, ver string) {
+				b.WriteString(fmt.Sprintf("HYPRLAND_%s_%s_VERSION=\"%s\"\n", upper, strings.ToUpper(lib), ver))
+			})
+		}
 		b.WriteString("\n")
 	}
 
-	err := os.WriteFile(file, []byte(b.String()), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write %s: %v", file, err)
+	for _, c := range components {
+		if c.ABI == nil {
+			continue
+		}
+		b.WriteString("# Libraries\n")
+		b.WriteString(fmt.Sprintf("HYPRLAND_LIBS_BINARY=\"%s\"\n", c.ABI.Binary))
+		b.WriteString(fmt.Sprintf("HYPRLAND_LIBS_ABI_OK=\"%t\"\n", c.ABI.OK))
+		b.WriteString(fmt.Sprintf("HYPRLAND_LIBS_MISSING=\"%s\"\n", strings.Join(c.ABI.Missing, " ")))
+		for _, l := range c.ABI.Libraries {
+			upper := "HYPRLAND_LIB_" + metaKeyName.Replace(strings.ToUpper(l.Name))
+			b.WriteString(fmt.Sprintf("%s_VERSION=\"%s\"\n", upper, l.Version))
+			b.WriteString(fmt.Sprintf("%s_SONAME=\"%s\"\n", upper, strings.Join(l.Sonames, " ")))
+			b.WriteString(fmt.Sprintf("%s_NEEDED=\"%s\"\n", upper, l.Needed))
+			b.WriteString(fmt.Sprintf("%s_BUILT_AGAINST=\"%s\"\n", upper, l.BuiltAgainst))
+			b.WriteString(fmt.Sprintf("%s_ABI=\"%s\"\n", upper, l.ABI))
+		}
+		b.WriteString("\n")
 	}
-	return nil
+	return b.String()
 }
@@ -0,0 +1,308 @@
+package check
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+// ABI states of a library
+const (
+	ABIOk       = "ok"
+	ABIMismatch = "mismatch"
+	ABIMissing  = "missing"
+	ABIUnknown  = "unknown"
+)
+
+// hyprLibraries : libraries whose ABI bumps break Hyprland, in report order
+var hyprLibraries = []string{"aquamarine", "hyprutils", "hyprlang", "hyprcursor", "hyprgraphics"}
+
+// ABIReport : libraries of a Hyprland binary: installed versions (pkg-config
+// .pc files and .so sonames) against what the binary was built and linked
+// against (build info and its ELF DT_NEEDED entries)
+type ABIReport struct {
+	// Binary : inspected executable
+	Binary    string          `json:"binary"`
+	Libraries []LibraryStatus `json:"libraries"`
+	// Missing : DT_NEEDED entries that no library directory has
+	Missing []string `json:"missing,omitempty"`
+	// OK : no mismatched or missing library
+	OK bool `json:"ok"`
+}
+
+// LibraryStatus : one hypr* library
+type LibraryStatus struct {
+	Name string `json:"name"`
+	// Version : from the .pc file; empty without development files
+	Version string `json:"version,omitempty"`
+	PCFile  string `json:"pc_file,omitempty"`
+	// Sonames : sonames of the installed shared objects
+	Sonames []string `json:"sonames,omitempty"`
+	// Needed : soname the binary is linked against
+	Needed string `json:"needed,omitempty"`
+	// BuiltAgainst : version from the build info
+	BuiltAgainst string `json:"built_against,omitempty"`
+	ABI          string `json:"abi"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// CheckLibraries : ABI report of the Hyprland binary at path; build may be
+// nil when no build info is available, then only sonames are compared
+func CheckLibraries(path string, build *BuildInfo) (*ABIReport, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", path, err)
+	}
+	defer f.Close()
+	needed, err := f.ImportedLibraries()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read dependencies of %s: %v", path, err)
+	}
+
+	dirs := librarySearchPath(f, path)
+	report := &ABIReport{Binary: path, OK: true}
+	for _, lib := range needed {
+		if findLibrary(dirs, lib) == "" {
+			report.Missing = append(report.Missing, lib)
+			report.OK = false
+		}
+	}
+
+	pcDirs := pkgConfigPath(dirs)
+	for _, name := range hyprLibraries {
+		s := LibraryStatus{Name: name, ABI: ABIUnknown}
+		if build != nil {
+			s.BuiltAgainst = build.Libraries.get(name)
+		}
+		if pc := findPCFile(pcDirs, name); pc != "" {
+			s.PCFile = pc
+			s.Version, _ = pcVersion(pc)
+		}
+		s.Sonames = installedSonames(dirs, "lib"+name+".so")
+		for _, lib := range needed {
+			if strings.HasPrefix(lib, "lib"+name+".so") {
+				s.Needed = lib
+			}
+		}
+		if s.Needed == "" && s.BuiltAgainst == "" && s.Version == "" && len(s.Sonames) == 0 {
+			// not used by this build and not installed
+			continue
+		}
+		s.ABI, s.Reason = libraryABI(s, report.Missing)
+		if s.ABI == ABIMismatch || s.ABI == ABIMissing {
+			report.OK = false
+		}
+		report.Libraries = append(report.Libraries, s)
+	}
+	return report, nil
+}
+
+// libraryABI : compares what the binary needs with what is installed. A
+// needed soname that cannot be found is missing; a .pc version whose ABI
+// series (major, or major.minor for 0.x) differs from the build's is a
+// mismatch.
+func libraryABI(s LibraryStatus, missing []string) (string, string) {
+	for _, m := range missing {
+		if m == s.Needed {
+			if len(s.Sonames) > 0 {
+				return ABIMissing, fmt.Sprintf("%s not found, installed: %s", s.Needed, strings.Join(s.Sonames, ", "))
+			}
+			return ABIMissing, fmt.Sprintf("%s not found", s.Needed)
+		}
+	}
+	if s.BuiltAgainst != "" && s.Version != "" {
+		built, err1 := version.Parse(s.BuiltAgainst)
+		installed, err2 := version.Parse(s.Version)
+		if err1 == nil && err2 == nil {
+			if abiSeries(built) != abiSeries(installed) {
+				return ABIMismatch, fmt.Sprintf("built against %s, installed %s", s.BuiltAgainst, s.Version)
+			}
+			return ABIOk, ""
+		}
+	}
+	if s.Needed != "" {
+		// linked soname is present
+		return ABIOk, ""
+	}
+	return ABIUnknown, ""
+}
+
+// abiSeries : versions with the same series share an ABI; hypr* libraries
+// bump their soname with every 0.x minor release
+func abiSeries(v version.Version) string {
+	major, minor := 0, 0
+	if len(v.Release) > 0 {
+		major = v.Release[0]
+	}
+	if len(v.Release) > 1 {
+		minor = v.Release[1]
+	}
+	if major == 0 {
+		return fmt.Sprintf("0.%d", minor)
+	}
+	return fmt.Sprintf("%d", major)
+}
+
+// librarySearchPath : directories the dynamic linker searches for the
+// binary: LD_LIBRARY_PATH, its RPATH/RUNPATH, /etc/ld.so.conf and the
+// default directories
+func librarySearchPath(f *elf.File, path string) []string {
+	var dirs []string
+	dirs = append(dirs, filepath.SplitList(os.Getenv("LD_LIBRARY_PATH"))...)
+	origin := filepath.Dir(path)
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, _ := f.DynString(tag)
+		for _, v := range values {
+			for _, dir := range filepath.SplitList(v) {
+				dir = strings.ReplaceAll(dir, "${ORIGIN}", origin)
+				dirs = append(dirs, strings.ReplaceAll(dir, "$ORIGIN", origin))
+			}
+		}
+	}
+	dirs = append(dirs, ldSoConf("/etc/ld.so.conf", 0)...)
+	dirs = append(dirs, "/lib64", "/usr/lib64", "/lib", "/usr/lib", "/usr/local/lib")
+
+	var out []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		out = append(out, dir)
+	}
+	return out
+}
+
+// ldSoConf : directories listed in an ld.so.conf file and its includes
+func ldSoConf(file string, depth int) []string {
+	if depth > 8 {
+		return nil
+	}
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer fh.Close()
+	var dirs []string
+	sc := bufio.NewScanner(fh)
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.SplitN(sc.Text(), "#", 2)[0])
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "include "):
+			pattern := strings.TrimSpace(strings.TrimPrefix(line, "include "))
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(filepath.Dir(file), pattern)
+			}
+			matches, _ := filepath.Glob(pattern)
+			sort.Strings(matches)
+			for _, m := range matches {
+				dirs = append(dirs, ldSoConf(m, depth+1)...)
+			}
+		case strings.HasPrefix(line, "hwcap "):
+		default:
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs
+}
+
+func findLibrary(dirs []string, soname string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, soname)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// installedSonames : DT_SONAME of every shared object named prefix* in dirs
+func installedSonames(dirs []string, prefix string) []string {
+	var sonames []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, prefix+"*"))
+		for _, m := range matches {
+			real, err := filepath.EvalSymlinks(m)
+			if err != nil || seen[real] {
+				continue
+			}
+			seen[real] = true
+			f, err := elf.Open(real)
+			if err != nil {
+				continue
+			}
+			names, _ := f.DynString(elf.DT_SONAME)
+			f.Close()
+			for _, name := range names {
+				if !seen["soname:"+name] {
+					seen["soname:"+name] = true
+					sonames = append(sonames, name)
+				}
+			}
+		}
+	}
+	sort.Strings(sonames)
+	return sonames
+}
+
+// pkgConfigPath : directories searched for .pc files, as pkg-config does:
+// PKG_CONFIG_PATH, then PKG_CONFIG_LIBDIR or the pkgconfig directories
+// next to the libraries
+func pkgConfigPath(libDirs []string) []string {
+	dirs := filepath.SplitList(os.Getenv("PKG_CONFIG_PATH"))
+	if libdir := os.Getenv("PKG_CONFIG_LIBDIR"); libdir != "" {
+		return append(dirs, filepath.SplitList(libdir)...)
+	}
+	for _, dir := range libDirs {
+		dirs = append(dirs, filepath.Join(dir, "pkgconfig"))
+	}
+	return append(dirs, "/usr/share/pkgconfig", "/usr/local/share/pkgconfig")
+}
+
+func findPCFile(dirs []string, name string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name+".pc")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// pcVersion : Version field of a .pc file, with ${variables} expanded
+func pcVersion(file string) (string, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+	vars := make(map[string]string)
+	expand := func(s string) string {
+		return os.Expand(s, func(k string) string { return vars[k] })
+	}
+	sc := bufio.NewScanner(fh)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, ":="); i > 0 {
+			key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+			if line[i] == '=' {
+				vars[key] = expand(value)
+			} else if key == "Version" {
+				return expand(value), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s: no Version field", file)
+}
@@ -0,0 +1,150 @@
+package check
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// packageManager : how one distro's package manager is asked for a
+// package version. The package name is appended to query; parse reads the
+// version from the output and returns "" when the package is not known.
+type packageManager struct {
+	query []string
+	parse func(out, pkg string) (string, error)
+}
+
+// packageManagers : distro paket yöneticilerinin sürüm sorguları
+var packageManagers = map[string]packageManager{
+	"arch":   {[]string{"pacman", "-Si"}, parsePacman},
+	"debian": {[]string{"apt-cache", "policy"}, parseAptPolicy},
+	"fedora": {[]string{"dnf", "info"}, parseDnfInfo},
+	"void":   {[]string{"xbps-query", "-R"}, parseXbps},
+	"gentoo": {[]string{"emerge", "-pv"}, parseEmerge},
+}
+
+// packageManagerDistros : packageManagers anahtarları, alfabetik sırayla;
+// map sırası her çalıştırmada değiştiğinden sorgular bu sırayla yapılır
+var packageManagerDistros = slices.Sorted(maps.Keys(packageManagers))
+
+// fields : "Key : value" lines of out, split into blocks at blank lines
+// (pacman -Si and dnf info print one block per package)
+func fields(out string) []map[string]string {
+	var blocks []map[string]string
+	block := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(block) > 0 {
+				blocks = append(blocks, block)
+				block = map[string]string{}
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			block[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// parsePacman : "Version : 1:0.45.2-1" of pacman -Si (epoch and pkgrel
+// included)
+func parsePacman(out, pkg string) (string, error) {
+	for _, b := range fields(out) {
+		if v := b["Version"]; v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("pacman: no Version field for %s", pkg)
+}
+
+// parseAptPolicy : candidate version of apt-cache policy, the installed
+// one when there is no candidate. apt-cache prints nothing for unknown
+// packages.
+func parseAptPolicy(out, pkg string) (string, error) {
+	if strings.TrimSpace(out) == "" {
+		return "", nil
+	}
+	b := map[string]string{}
+	for _, block := range fields(out) {
+		maps.Copy(b, block)
+	}
+	for _, key := range []string{"Candidate", "Installed"} {
+		if v := b[key]; v != "" && v != "(none)" {
+			return v, nil
+		}
+	}
+	if _, ok := b["Candidate"]; ok {
+		return "", nil
+	}
+	return "", fmt.Errorf("apt-cache policy: no Installed or Candidate line for %s", pkg)
+}
+
+// parseDnfInfo : Epoch:Version-Release of dnf info. The last package
+// listed is used: dnf prints the installed package first and a newer
+// available one after it.
+func parseDnfInfo(out, pkg string) (string, error) {
+	var v string
+	for _, b := range fields(out) {
+		if b["Version"] == "" {
+			continue
+		}
+		if b["Release"] == "" {
+			return "", fmt.Errorf("dnf info: %s %s has no Release field", pkg, b["Version"])
+		}
+		v = b["Version"] + "-" + b["Release"]
+		if e := b["Epoch"]; e != "" && e != "0" {
+			v = e + ":" + v
+		}
+	}
+	if v == "" {
+		return "", fmt.Errorf("dnf info: no Version field for %s", pkg)
+	}
+	return v, nil
+}
+
+// parseXbps : version of "pkgver: hyprland-0.45.2_1" in xbps-query -R
+func parseXbps(out, pkg string) (string, error) {
+	for _, b := range fields(out) {
+		pkgver := b["pkgver"]
+		if pkgver == "" {
+			continue
+		}
+		i := strings.LastIndex(pkgver, "-")
+		if i < 0 || i == len(pkgver)-1 {
+			return "", fmt.Errorf("xbps-query: invalid pkgver %q", pkgver)
+		}
+		return pkgver[i+1:], nil
+	}
+	return "", fmt.Errorf("xbps-query: no pkgver field for %s", pkg)
+}
+
+var (
+	// "[ebuild  N     ] gui-wm/hyprland-0.45.2-r1::gentoo  USE=..."
+	emergeLine = regexp.MustCompile(`(?m)^\[[^\]]*\]\s+(\S+)`)
+	// version at the end of a Gentoo atom, with an optional -rN revision
+	atomVersion = regexp.MustCompile(`-(\d[^-]*(?:-r\d+)?)$`)
+)
+
+// parseEmerge : version of the package emerge -pv would merge
+func parseEmerge(out, pkg string) (string, error) {
+	m := emergeLine.FindStringSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("emerge: no package line for %s", pkg)
+	}
+	atom, _, _ := strings.Cut(m[1], "::")
+	v := atomVersion.FindStringSubmatch(atom)
+	if v == nil {
+		return "", fmt.Errorf("emerge: no version in %q", atom)
+	}
+	return v[1], nil
+}
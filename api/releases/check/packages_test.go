@@ -0,0 +1,110 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+const (
+	pacmanOut = `Repository      : extra
+Name            : hyprland
+Version         : 1:0.45.2-1
+Description     : a highly customizable dynamic tiling Wayland compositor
+Depends On      : aquamarine  cairo  hyprcursor
+Packager        : Someone <someone@archlinux.org>
+Build Date      : Sat 16 Nov 2024 15:10:02 CET
+`
+	aptOut = `hyprland:
+  Installed: 0.41.2+ds-1
+  Candidate: 0.45.2+ds-1
+  Version table:
+     0.45.2+ds-1 500
+        500 http://deb.debian.org/debian sid/main amd64 Packages
+ *** 0.41.2+ds-1 100
+        100 /var/lib/dpkg/status
+`
+	aptInstalledOnly = `hyprland:
+  Installed: 0.41.2+ds-1
+  Candidate: (none)
+  Version table:
+ *** 0.41.2+ds-1 100
+        100 /var/lib/dpkg/status
+`
+	aptUnavailable = `hyprland:
+  Installed: (none)
+  Candidate: (none)
+  Version table:
+`
+	dnfOut = `Installed Packages
+Name         : hyprland
+Version      : 0.41.2
+Release      : 1.fc40
+Architecture : x86_64
+Description  : Dynamic tiling Wayland compositor: with
+             : eye candy
+
+Available Packages
+Name         : hyprland
+Epoch        : 1
+Version      : 0.45.2
+Release      : 1.fc41
+Architecture : x86_64
+`
+	xbpsOut = `architecture: x86_64
+filename-size: 1546KB
+pkgver: hyprland-0.45.2_1
+repository: https://repo-default.voidlinux.org/current
+short_desc: Dynamic tiling Wayland compositor
+`
+	emergeOut = `
+These are the packages that would be merged, in order:
+
+Calculating dependencies... done!
+[ebuild  N     ] gui-wm/hyprland-0.45.2-r1::gentoo  USE="X -legacy-renderer" 0 KiB
+
+Total: 1 package (1 new), Size of downloads: 0 KiB
+`
+)
+
+func TestPackageManagerParsers(t *testing.T) {
+	tests := []struct {
+		name   string
+		parse  func(out, pkg string) (string, error)
+		out    string
+		want   string
+		hasErr bool
+	}{
+		{"pacman", parsePacman, pacmanOut, "1:0.45.2-1", false},
+		{"pacman without Version", parsePacman, "Name : hyprland\n", "", true},
+		{"apt candidate", parseAptPolicy, aptOut, "0.45.2+ds-1", false},
+		{"apt installed only", parseAptPolicy, aptInstalledOnly, "0.41.2+ds-1", false},
+		{"apt unavailable", parseAptPolicy, aptUnavailable, "", false},
+		{"apt unknown package", parseAptPolicy, "", "", false},
+		{"apt unexpected output", parseAptPolicy, "N: Unable to locate package\n", "", true},
+		{"dnf newest with epoch and release", parseDnfInfo, dnfOut, "1:0.45.2-1.fc41", false},
+		{"dnf without Release", parseDnfInfo, "Name : hyprland\nVersion : 0.45.2\n", "", true},
+		{"dnf without Version", parseDnfInfo, "Error: No matching Packages to list\n", "", true},
+		{"xbps", parseXbps, xbpsOut, "0.45.2_1", false},
+		{"xbps invalid pkgver", parseXbps, "pkgver: hyprland\n", "", true},
+		{"emerge", parseEmerge, emergeOut, "0.45.2-r1", false},
+		{"emerge without package", parseEmerge, "Calculating dependencies... done!\n", "", true},
+		// a line with "version" but no colon used to panic
+		{"no colon", parsePacman, "Version\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.out, "hyprland")
+			if got != tt.want || (err != nil) != tt.hasErr {
+				t.Fatalf("got %q, %v; want %q (error %t)", got, err, tt.want, tt.hasErr)
+			}
+			if got == "" {
+				return
+			}
+			// every result is a version the version package understands
+			if v, err := version.Parse(got); err != nil || v.Release[1] == 0 {
+				t.Errorf("version.Parse(%q) = %+v, %v", got, v, err)
+			}
+		})
+	}
+}
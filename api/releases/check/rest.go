@@ -0,0 +1,257 @@
+package check
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound : the repository (or release, tag, ref) does not exist on the
+// forge, or is private and no token was given
+var ErrNotFound = errors.New("not found")
+
+// RateLimit : API quota as of the last response
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// RateLimitError : the quota is used up until Reset
+type RateLimitError struct {
+	// API : which API, e.g. "GitHub"
+	API   string
+	Reset time.Time
+	// Authenticated : whether the request carried a token
+	Authenticated bool
+	// Hint : how to get a token, shown when unauthenticated
+	Hint string
+}
+
+func (e *RateLimitError) Error() string {
+	msg := e.API + " API rate limit exceeded until " + e.Reset.Local().Format("15:04")
+	if !e.Authenticated && e.Hint != "" {
+		msg += " (" + e.Hint + ")"
+	}
+	return msg
+}
+
+// restAPI : one REST API root and how to talk to it
+type restAPI struct {
+	// name : API name used in errors
+	name     string
+	base     string
+	token    string
+	cacheDir string
+	http     *http.Client
+	// header : request headers (Accept, authentication)
+	header http.Header
+	// hint : RateLimitError.Hint
+	hint string
+}
+
+// restCache : ETag cache and rate limit state shared by the requests of one
+// client. Responses are revalidated with If-None-Match (not counted against
+// the quota by GitHub and GitLab); while the quota is used up, or the API
+// cannot be reached, cached responses are served and uncached requests fail.
+type restCache struct {
+	mu    sync.Mutex
+	memo  map[string]cachedResponse
+	limit RateLimit
+}
+
+type cachedResponse struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// rateLimit : quota reported by the last response (zero before any request)
+func (c *restCache) rateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// get : GET path and decode the JSON response into v
+func (c *restCache) get(ctx context.Context, api restAPI, path string, v any) error {
+	endpoint := strings.TrimRight(api.base, "/") + path
+	cached, hasCache := c.load(api, endpoint)
+
+	limit := c.rateLimit()
+	if limit.Limit > 0 && limit.Remaining == 0 && time.Now().Before(limit.Reset) {
+		if hasCache {
+			return json.Unmarshal(cached.Body, v)
+		}
+		return &RateLimitError{API: api.name, Reset: limit.Reset, Authenticated: api.token != "", Hint: api.hint}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for k, vals := range api.header {
+		req.Header[k] = vals
+	}
+	req.Header.Set("User-Agent", "hypr-release")
+	if hasCache && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	client := api.http
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if hasCache {
+			return json.Unmarshal(cached.Body, v)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	c.updateLimit(resp.Header)
+
+	route := strings.SplitN(path, "?", 2)[0]
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCache:
+		return json.Unmarshal(cached.Body, v)
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, v); err != nil {
+			return fmt.Errorf("invalid %s response for %s: %v", api.name, route, err)
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			c.store(api, endpoint, cachedResponse{ETag: etag, Body: body})
+		}
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w on %s", route, ErrNotFound, api.name)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if reset, limited := rateLimited(resp); limited {
+			if hasCache {
+				return json.Unmarshal(cached.Body, v)
+			}
+			return &RateLimitError{API: api.name, Reset: reset, Authenticated: api.token != "", Hint: api.hint}
+		}
+	}
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&apiErr)
+	if apiErr.Message == "" {
+		apiErr.Message = resp.Status
+	}
+	return fmt.Errorf("%s API %s: %s", api.name, route, apiErr.Message)
+}
+
+// rateLimitHeader : value of a rate limit header in GitHub (X-RateLimit-*)
+// or GitLab (RateLimit-*) spelling
+func rateLimitHeader(h http.Header, name string) string {
+	if v := h.Get("X-RateLimit-" + name); v != "" {
+		return v
+	}
+	return h.Get("RateLimit-" + name)
+}
+
+func (c *restCache) updateLimit(h http.Header) {
+	remaining, err := strconv.Atoi(rateLimitHeader(h, "Remaining"))
+	if err != nil {
+		return
+	}
+	l := RateLimit{Remaining: remaining}
+	l.Limit, _ = strconv.Atoi(rateLimitHeader(h, "Limit"))
+	if reset, err := strconv.ParseInt(rateLimitHeader(h, "Reset"), 10, 64); err == nil {
+		l.Reset = time.Unix(reset, 0)
+	}
+	c.mu.Lock()
+	c.limit = l
+	c.mu.Unlock()
+}
+
+// rateLimited : whether a 403/429 is a rate limit (primary or secondary)
+// rather than a permission error, and when it ends
+func rateLimited(resp *http.Response) (time.Time, bool) {
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if sec, err := strconv.Atoi(s); err == nil {
+			return time.Now().Add(time.Duration(sec) * time.Second), true
+		}
+	}
+	if rateLimitHeader(resp.Header, "Remaining") == "0" {
+		reset, _ := strconv.ParseInt(rateLimitHeader(resp.Header, "Reset"), 10, 64)
+		return time.Unix(reset, 0), true
+	}
+	return time.Time{}, false
+}
+
+func (c *restCache) load(api restAPI, endpoint string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r, ok := c.memo[endpoint]; ok {
+		return r, true
+	}
+	if api.cacheDir == "" {
+		return cachedResponse{}, false
+	}
+	data, err := os.ReadFile(cachePath(api, endpoint))
+	if err != nil {
+		return cachedResponse{}, false
+	}
+	var r cachedResponse
+	if json.Unmarshal(data, &r) != nil || len(r.Body) == 0 {
+		return cachedResponse{}, false
+	}
+	return r, true
+}
+
+func (c *restCache) store(api restAPI, endpoint string, r cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.memo == nil {
+		c.memo = make(map[string]cachedResponse)
+	}
+	c.memo[endpoint] = r
+	if api.cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil || os.MkdirAll(api.cacheDir, 0700) != nil {
+		return
+	}
+	path := cachePath(api, endpoint)
+	if os.WriteFile(path+".tmp", data, 0600) == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// cachePath : cache file of an endpoint; responses seen with a token are
+// kept apart from anonymous ones
+func cachePath(api restAPI, endpoint string) string {
+	key := endpoint
+	if api.token != "" {
+		key += "\x00auth"
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(api.cacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func perPage(limit int) int {
+	if limit <= 0 || limit > 100 {
+		return 100
+	}
+	// drafts are filtered after the fact; ask for a few more
+	return min(limit+5, 100)
+}
@@ -0,0 +1,123 @@
+// Package config : hypr-release settings, read from config.toml under
+// /etc/hypr-release and $XDG_CONFIG_HOME/hypr-release (the user file wins)
+// and overridden by HYPR_RELEASE_* environment variables:
+//
+//	[git]
+//	backend = "auto"    # exec (git binary), native (built in) or auto
+//
+//	[github]
+//	token = ""                           # or HYPR_RELEASE_GITHUB_TOKEN, GITHUB_TOKEN, GH_TOKEN
+//	api_url = "https://api.github.com"   # GitHub Enterprise: https://host/api/v3
+//
+//	[[forge]]                            # self-hosted forges and their tokens
+//	host = "git.example.org"
+//	type = "gitea"                       # github, gitlab, gitea or git
+//	token = ""
+//	api_url = ""                         # default: https://host/api/v4 (gitlab), /api/v1 (gitea)
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// Environment overrides
+const (
+	// GitBackendEnv : overrides [git] backend
+	GitBackendEnv = "HYPR_RELEASE_GIT_BACKEND"
+	// GitHubTokenEnv : overrides [github] token
+	GitHubTokenEnv = "HYPR_RELEASE_GITHUB_TOKEN"
+	// GitHubAPIEnv : overrides [github] api_url
+	GitHubAPIEnv = "HYPR_RELEASE_GITHUB_API"
+)
+
+// DefaultGitHubAPI : GitHub REST API base URL
+const DefaultGitHubAPI = "https://api.github.com"
+
+// Config : every setting; the zero value means defaults
+type Config struct {
+	Git    Git     `toml:"git" json:"git"`
+	GitHub GitHub  `toml:"github" json:"github"`
+	Forges []Forge `toml:"forge" json:"forges,omitempty"`
+}
+
+// Git : how git repositories are accessed
+type Git struct {
+	// Backend : "exec" runs the git binary, "native" uses the built-in
+	// implementation, "auto" (or empty) uses git when it is installed
+	Backend string `toml:"backend" json:"backend,omitempty"`
+}
+
+// GitHub : access to the GitHub REST API
+type GitHub struct {
+	// Token : optional personal access token; raises the rate limit and
+	// allows private repositories
+	Token string `toml:"token" json:"-"`
+	// APIURL : REST API base URL; empty means DefaultGitHubAPI
+	APIURL string `toml:"api_url" json:"api_url,omitempty"`
+}
+
+// Forge : a forge host that cannot be recognized from its name, or the
+// token to use for one
+type Forge struct {
+	Host string `toml:"host" json:"host"`
+	// Type : github, gitlab, gitea (also Forgejo) or git; empty means
+	// detect from the host name
+	Type  string `toml:"type" json:"type,omitempty"`
+	Token string `toml:"token" json:"-"`
+	// APIURL : REST API base URL; empty means the forge's default path on host
+	APIURL string `toml:"api_url" json:"api_url,omitempty"`
+}
+
+// Forge : the [[forge]] entry for host, if any
+func (c Config) Forge(host string) (Forge, bool) {
+	for _, f := range c.Forges {
+		if strings.EqualFold(f.Host, host) {
+			return f, true
+		}
+	}
+	return Forge{}, false
+}
+
+// Files : config.toml files, lowest precedence first
+func Files() []string {
+	return []string{
+		"/etc/hypr-release/config.toml",
+		filepath.Join(paths.ConfigDir(), "config.toml"),
+	}
+}
+
+// Load : reads every existing config file in order, later files
+// overriding the keys they set, then applies the environment
+func Load() (Config, error) {
+	var c Config
+	for _, path := range Files() {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if _, err := toml.DecodeFile(path, &c); err != nil {
+			return c, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv(GitBackendEnv)); v != "" {
+		c.Git.Backend = v
+	}
+	for _, env := range []string{GitHubTokenEnv, "GITHUB_TOKEN", "GH_TOKEN"} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			c.GitHub.Token = v
+			break
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv(GitHubAPIEnv)); v != "" {
+		c.GitHub.APIURL = v
+	}
+	if c.GitHub.APIURL == "" {
+		c.GitHub.APIURL = DefaultGitHubAPI
+	}
+	return c, nil
+}
@@ -0,0 +1,179 @@
+// Package gitbackend : the git operations hypr-release needs (clone, fetch,
+// ls-remote, describe, rev-list counts, branches and checkouts) behind one
+// interface. Exec runs the git binary; Native is a pure Go implementation
+// for systems without git. Which one is used comes from config ([git]
+// backend or HYPR_RELEASE_GIT_BACKEND).
+package gitbackend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/config"
+)
+
+// Backend names accepted in config
+const (
+	BackendAuto   = "auto"
+	BackendExec   = "exec"
+	BackendNative = "native"
+)
+
+// Ref : a remote reference. Target is set for symbolic refs (HEAD).
+type Ref struct {
+	Name   string `json:"name"`
+	Hash   string `json:"hash,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// Submodule : a submodule of a checkout and the commit it is at
+type Submodule struct {
+	Path   string `json:"path"`
+	URL    string `json:"url,omitempty"`
+	Commit string `json:"commit"`
+}
+
+// Commit : a commit, who wrote it and when it was committed
+type Commit struct {
+	Hash   string    `json:"hash"`
+	Author string    `json:"author"`
+	Time   time.Time `json:"time"`
+}
+
+// CloneOptions : how Clone fetches the repository
+type CloneOptions struct {
+	// Branch : branch (or tag) to clone; empty means the remote HEAD
+	Branch string
+	// Depth : history depth; 0 means full history
+	Depth int
+	// NoCheckout : leave the worktree empty
+	NoCheckout bool
+	// Progress : receives progress output; nil discards it
+	Progress io.Writer
+}
+
+// FetchOptions : how Fetch updates origin. Branches go to
+// refs/remotes/origin/*, tags are fetched and replaced, and deleted remote
+// branches are pruned.
+type FetchOptions struct {
+	// URL : when set and different, origin is pointed here first
+	URL string
+	// Progress : receives progress output; nil discards it
+	Progress io.Writer
+}
+
+// SubmoduleOptions : how UpdateSubmodules fetches submodules
+type SubmoduleOptions struct {
+	// Offline : only use objects fetched earlier
+	Offline bool
+	// Progress : receives progress output; nil discards it
+	Progress io.Writer
+}
+
+// Backend : git operations on local checkouts (dir) and remotes (url)
+type Backend interface {
+	// Name : backend name, as used in config
+	Name() string
+	// Clone : clones url into dir with origin as the remote
+	Clone(ctx context.Context, url, dir string, opts CloneOptions) error
+	// Fetch : fetches branches and tags from origin
+	Fetch(ctx context.Context, dir string, opts FetchOptions) error
+	// LsRemote : references of a remote repository; annotated tags are
+	// followed by their peeled "name^{}" entry
+	LsRemote(ctx context.Context, url string) ([]Ref, error)
+	// Describe : nearest tag of rev as "tag", "tag-N-gabcdef1", or the
+	// abbreviated commit when no tag is reachable (git describe --tags --always)
+	Describe(ctx context.Context, dir, rev string) (string, error)
+	// RevListCount : commits reachable from to but not from from
+	RevListCount(ctx context.Context, dir, from, to string) (int, error)
+	// CurrentBranch : checked out branch, or "HEAD" when detached
+	CurrentBranch(ctx context.Context, dir string) (string, error)
+	// Upstream : remote-tracking branch the checked out branch tracks,
+	// e.g. "origin/main"; an error when it tracks none or HEAD is detached
+	Upstream(ctx context.Context, dir string) (string, error)
+	// Dirty : whether the worktree has modified, staged or untracked
+	// (not ignored) files
+	Dirty(ctx context.Context, dir string) (bool, error)
+	// Resolve : commit hash rev points to
+	Resolve(ctx context.Context, dir, rev string) (string, error)
+	// Commit : the commit rev points to
+	Commit(ctx context.Context, dir, rev string) (Commit, error)
+	// LastCommitBefore : last commit on rev's first-parent history
+	// committed before t; empty when there is none
+	LastCommitBefore(ctx context.Context, dir, rev string, t time.Time) (string, error)
+	// Checkout : forces the worktree to rev and removes untracked files.
+	// With branch set, that branch is created or reset to rev and checked
+	// out; otherwise HEAD is detached.
+	Checkout(ctx context.Context, dir, rev, branch string) error
+	// RemoteURL : URL of origin
+	RemoteURL(ctx context.Context, dir string) (string, error)
+	// UpdateSubmodules : initializes and updates submodules (recursively)
+	// to the commits the checkout pins
+	UpdateSubmodules(ctx context.Context, dir string, opts SubmoduleOptions) error
+	// Submodules : submodules of a checkout (recursively)
+	Submodules(ctx context.Context, dir string) ([]Submodule, error)
+}
+
+var (
+	mu      sync.Mutex
+	current Backend
+)
+
+// New : backend by name; "auto" or empty picks Exec when git is installed
+// and Native otherwise
+func New(name string) (Backend, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", BackendAuto:
+		if _, err := exec.LookPath("git"); err == nil {
+			return Exec{}, nil
+		}
+		return Native{}, nil
+	case BackendExec, "git":
+		return Exec{}, nil
+	case BackendNative, "go-git":
+		return Native{}, nil
+	}
+	return nil, fmt.Errorf("unknown git backend %q (use %s, %s or %s)", name, BackendExec, BackendNative, BackendAuto)
+}
+
+// Default : backend selected by config, created on first use. An invalid
+// config falls back to auto with a warning.
+func Default() Backend {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		return current
+	}
+	c, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "⚠️ config:", err)
+	}
+	b, err := New(c.Git.Backend)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "⚠️", err)
+		b, _ = New(BackendAuto)
+	}
+	current = b
+	return current
+}
+
+// SetDefault : replaces the backend returned by Default (nil re-reads config)
+func SetDefault(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = b
+}
+
+// abbrev : commit hash shortened like git's --abbrev=7
+func abbrev(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
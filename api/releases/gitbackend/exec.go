@@ -0,0 +1,232 @@
+package gitbackend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exec : runs the git binary found in PATH
+type Exec struct{}
+
+func (Exec) Name() string { return BackendExec }
+
+func (e Exec) Clone(ctx context.Context, url, dir string, opts CloneOptions) error {
+	args := []string{"clone"}
+	if opts.Branch != "" {
+		args = append(args, "-b", opts.Branch)
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth="+strconv.Itoa(opts.Depth))
+	}
+	if opts.NoCheckout {
+		args = append(args, "--no-checkout")
+	}
+	return e.run(ctx, opts.Progress, "", append(args, "--", url, dir)...)
+}
+
+func (e Exec) Fetch(ctx context.Context, dir string, opts FetchOptions) error {
+	if opts.URL != "" {
+		if remote, _ := e.RemoteURL(ctx, dir); remote != opts.URL {
+			if err := e.run(ctx, nil, dir, "remote", "set-url", "origin", opts.URL); err != nil {
+				return err
+			}
+		}
+	}
+	return e.run(ctx, opts.Progress, dir, "fetch", "--prune", "--tags", "--force", "origin")
+}
+
+func (e Exec) LsRemote(ctx context.Context, url string) ([]Ref, error) {
+	out, err := e.output(ctx, "", "ls-remote", "--symref", "--", url)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for _, line := range strings.Split(out, "\n") {
+		if target, ok := strings.CutPrefix(line, "ref: "); ok {
+			// "ref: refs/heads/main	HEAD"
+			if f := strings.Fields(target); len(f) == 2 {
+				refs = append(refs, Ref{Name: f[1], Target: f[0]})
+			}
+			continue
+		}
+		if f := strings.Fields(line); len(f) == 2 {
+			refs = append(refs, Ref{Name: f[1], Hash: f[0]})
+		}
+	}
+	// the symref line comes before the hash of HEAD; merge them
+	merged := refs[:0]
+	index := make(map[string]int)
+	for _, r := range refs {
+		if i, ok := index[r.Name]; ok {
+			if r.Hash != "" {
+				merged[i].Hash = r.Hash
+			}
+			if r.Target != "" {
+				merged[i].Target = r.Target
+			}
+			continue
+		}
+		index[r.Name] = len(merged)
+		merged = append(merged, r)
+	}
+	return merged, nil
+}
+
+func (e Exec) Describe(ctx context.Context, dir, rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	return e.output(ctx, dir, "describe", "--tags", "--abbrev=7", "--always", rev)
+}
+
+func (e Exec) RevListCount(ctx context.Context, dir, from, to string) (int, error) {
+	out, err := e.output(ctx, dir, "rev-list", "--count", from+".."+to)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+func (e Exec) CurrentBranch(ctx context.Context, dir string) (string, error) {
+	return e.output(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+func (e Exec) Upstream(ctx context.Context, dir string) (string, error) {
+	return e.output(ctx, dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+}
+
+func (e Exec) Dirty(ctx context.Context, dir string) (bool, error) {
+	out, err := e.output(ctx, dir, "status", "--porcelain")
+	return out != "", err
+}
+
+func (e Exec) Resolve(ctx context.Context, dir, rev string) (string, error) {
+	out, err := e.output(ctx, dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil || out == "" {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return out, nil
+}
+
+func (e Exec) Commit(ctx context.Context, dir, rev string) (Commit, error) {
+	hash, err := e.Resolve(ctx, dir, rev)
+	if err != nil {
+		return Commit{}, err
+	}
+	out, err := e.output(ctx, dir, "log", "-1", "--format=%an%x00%ct", hash)
+	if err != nil {
+		return Commit{}, err
+	}
+	author, ts, _ := strings.Cut(out, "\x00")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Commit{}, fmt.Errorf("unexpected git log output %q", out)
+	}
+	return Commit{Hash: hash, Author: author, Time: time.Unix(sec, 0)}, nil
+}
+
+func (e Exec) LastCommitBefore(ctx context.Context, dir, rev string, t time.Time) (string, error) {
+	return e.output(ctx, dir, "rev-list", "-1", "--first-parent", "--before="+t.Format(time.RFC3339), rev)
+}
+
+func (e Exec) Checkout(ctx context.Context, dir, rev, branch string) error {
+	args := []string{"checkout", "--quiet", "--force", "--detach", rev}
+	if branch != "" {
+		args = []string{"checkout", "--quiet", "--force", "-B", branch, rev}
+	}
+	if err := e.run(ctx, nil, dir, args...); err != nil {
+		return err
+	}
+	return e.run(ctx, nil, dir, "clean", "-ffdxq")
+}
+
+func (e Exec) RemoteURL(ctx context.Context, dir string) (string, error) {
+	return e.output(ctx, dir, "remote", "get-url", "origin")
+}
+
+func (e Exec) UpdateSubmodules(ctx context.Context, dir string, opts SubmoduleOptions) error {
+	if err := e.run(ctx, opts.Progress, dir, "submodule", "sync", "--quiet", "--recursive"); err != nil {
+		return fmt.Errorf("submodule sync failed: %v", err)
+	}
+	update := []string{"submodule", "update", "--init", "--recursive", "--force"}
+	if opts.Offline {
+		return e.run(ctx, opts.Progress, dir, append(update, "--no-fetch")...)
+	}
+	if err := e.run(ctx, opts.Progress, dir, append(update, "--depth=1")...); err != nil {
+		// pinned commits that are not a branch tip cannot always be
+		// fetched shallowly; fall back to full submodule history
+		return e.run(ctx, opts.Progress, dir, update...)
+	}
+	return nil
+}
+
+func (e Exec) Submodules(ctx context.Context, dir string) ([]Submodule, error) {
+	out, err := e.output(ctx, dir, "submodule", "status", "--recursive")
+	if err != nil {
+		return nil, err
+	}
+	var subs []Submodule
+	for _, line := range strings.Split(out, "\n") {
+		// " <sha> <path> (<describe>)", prefixed with -, + or U when not in sync
+		f := strings.Fields(strings.TrimLeft(line, " -+U"))
+		if len(f) < 2 {
+			continue
+		}
+		s := Submodule{Path: f[1], Commit: f[0]}
+		s.URL, _ = e.RemoteURL(ctx, filepath.Join(dir, s.Path))
+		subs = append(subs, s)
+	}
+	return subs, nil
+}
+
+// run : runs git in dir (empty: current directory); progress receives
+// git's output, and errors carry the first line of its stderr
+func (Exec) run(ctx context.Context, progress io.Writer, dir string, args ...string) error {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr strings.Builder
+	if progress != nil {
+		cmd.Stdout = progress
+		cmd.Stderr = io.MultiWriter(progress, &stderr)
+	} else {
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return gitError(stderr.String(), err)
+	}
+	return nil
+}
+
+func (Exec) output(ctx context.Context, dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", gitError(stderr.String(), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func gitError(stderr string, err error) error {
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return fmt.Errorf("%s", line)
+		}
+	}
+	return err
+}
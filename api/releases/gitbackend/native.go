@@ -0,0 +1,563 @@
+package gitbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Native : pure Go implementation (go-git); needs no git binary for
+// http(s) and ssh remotes. It does not run hooks, filters or Git LFS.
+// The zero value keeps repositories on disk; with Storage set, dir only
+// names a repository and Storage provides its objects and worktree.
+type Native struct {
+	// Storage : storer and worktree of the repository called dir; nil
+	// means the directory on disk
+	Storage func(dir string) (storage.Storer, billy.Filesystem)
+}
+
+// NewMemory : Native backend that keeps every repository in memory
+// (memory.NewStorage with a memfs worktree), one per dir
+func NewMemory() Native {
+	type repo struct {
+		s  storage.Storer
+		fs billy.Filesystem
+	}
+	var mu sync.Mutex
+	repos := make(map[string]repo)
+	return Native{Storage: func(dir string) (storage.Storer, billy.Filesystem) {
+		mu.Lock()
+		defer mu.Unlock()
+		r, ok := repos[dir]
+		if !ok {
+			r = repo{s: memory.NewStorage(), fs: memfs.New()}
+			repos[dir] = r
+		}
+		return r.s, r.fs
+	}}
+}
+
+// open : the repository at dir
+func (n Native) open(dir string) (*git.Repository, error) {
+	if n.Storage == nil {
+		return git.PlainOpen(dir)
+	}
+	s, fs := n.Storage(dir)
+	return git.Open(s, fs)
+}
+
+func (n Native) Name() string { return BackendNative }
+
+func (n Native) Clone(ctx context.Context, url, dir string, opts CloneOptions) error {
+	o := &git.CloneOptions{
+		URL:        url,
+		Depth:      opts.Depth,
+		NoCheckout: opts.NoCheckout,
+		Progress:   opts.Progress,
+		Tags:       git.AllTags,
+	}
+	if opts.Branch != "" {
+		o.ReferenceName = plumbing.NewBranchReferenceName(opts.Branch)
+		// like git clone -b, accept a tag when there is no such branch
+		if refs, err := n.LsRemote(ctx, url); err == nil {
+			names := make(map[string]bool)
+			for _, r := range refs {
+				names[r.Name] = true
+			}
+			if tag := plumbing.NewTagReferenceName(opts.Branch); !names[o.ReferenceName.String()] && names[tag.String()] {
+				o.ReferenceName = tag
+			}
+		}
+	}
+	if n.Storage == nil {
+		_, err := git.PlainCloneContext(ctx, dir, false, o)
+		return err
+	}
+	s, fs := n.Storage(dir)
+	_, err := git.CloneContext(ctx, s, fs, o)
+	return err
+}
+
+func (n Native) Fetch(ctx context.Context, dir string, opts FetchOptions) error {
+	r, err := n.open(dir)
+	if err != nil {
+		return err
+	}
+	if opts.URL != "" {
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+		origin, ok := cfg.Remotes["origin"]
+		if !ok {
+			return fmt.Errorf("%s has no origin remote", dir)
+		}
+		if len(origin.URLs) == 0 || origin.URLs[0] != opts.URL {
+			origin.URLs = []string{opts.URL}
+			if err := r.SetConfig(cfg); err != nil {
+				return err
+			}
+		}
+	}
+	err = r.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs: []gitconfig.RefSpec{
+			"+refs/heads/*:refs/remotes/origin/*",
+			"+refs/tags/*:refs/tags/*",
+		},
+		Tags:     git.AllTags,
+		Force:    true,
+		Prune:    true,
+		Progress: opts.Progress,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+func (n Native) LsRemote(ctx context.Context, url string) ([]Ref, error) {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{url}})
+	// peeled tags (name^{}) are listed like git ls-remote does
+	list, err := remote.ListContext(ctx, &git.ListOptions{PeelingOption: git.AppendPeeled})
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[plumbing.ReferenceName]string)
+	for _, r := range list {
+		if r.Type() == plumbing.HashReference {
+			hashes[r.Name()] = r.Hash().String()
+		}
+	}
+	refs := make([]Ref, 0, len(list))
+	for _, r := range list {
+		if r.Type() == plumbing.SymbolicReference {
+			refs = append(refs, Ref{Name: r.Name().String(), Target: r.Target().String(), Hash: hashes[r.Target()]})
+		} else {
+			refs = append(refs, Ref{Name: r.Name().String(), Hash: r.Hash().String()})
+		}
+	}
+	return refs, nil
+}
+
+// Describe : walks history from rev newest first and stops at the first
+// tagged commit. This matches git describe on linear histories; across
+// merges git may pick a different tag at the same distance.
+func (n Native) Describe(ctx context.Context, dir, rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	r, err := n.open(dir)
+	if err != nil {
+		return "", err
+	}
+	start, err := resolve(r, rev)
+	if err != nil {
+		return "", err
+	}
+
+	tags := make(map[plumbing.Hash]string)
+	iter, err := r.Tags()
+	if err != nil {
+		return "", err
+	}
+	iter.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		if tag, err := r.TagObject(target); err == nil {
+			c, err := tag.Commit()
+			if err != nil {
+				return nil
+			}
+			target = c.Hash
+		}
+		// several tags on one commit: keep the one that sorts last
+		if name := ref.Name().Short(); name > tags[target] {
+			tags[target] = name
+		}
+		return nil
+	})
+
+	log, err := r.Log(&git.LogOptions{From: start, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return "", err
+	}
+	found, depth := "", 0
+	err = log.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if name, ok := tags[c.Hash]; ok {
+			found = name
+			return storer.ErrStop
+		}
+		depth++
+		return nil
+	})
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		// a shallow history ends with missing parents; anything else is real
+		return "", err
+	}
+	switch {
+	case found == "":
+		return abbrev(start.String()), nil
+	case depth == 0:
+		return found, nil
+	}
+	return fmt.Sprintf("%s-%d-g%s", found, depth, abbrev(start.String())), nil
+}
+
+func (n Native) RevListCount(ctx context.Context, dir, from, to string) (int, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return 0, err
+	}
+	fromHash, err := resolve(r, from)
+	if err != nil {
+		return 0, err
+	}
+	toHash, err := resolve(r, to)
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[plumbing.Hash]bool)
+	if err := walk(ctx, r, fromHash, func(c *object.Commit) { seen[c.Hash] = true }); err != nil {
+		return 0, err
+	}
+	count := 0
+	err = walk(ctx, r, toHash, func(c *object.Commit) {
+		if !seen[c.Hash] {
+			count++
+		}
+	})
+	return count, err
+}
+
+func (n Native) CurrentBranch(ctx context.Context, dir string) (string, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return "", err
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short(), nil
+	}
+	return "HEAD", nil
+}
+
+func (n Native) Upstream(ctx context.Context, dir string) (string, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is detached")
+	}
+	cfg, err := r.Config()
+	if err != nil {
+		return "", err
+	}
+	b, ok := cfg.Branches[head.Name().Short()]
+	if !ok || b.Merge == "" {
+		return "", fmt.Errorf("branch %s has no upstream", head.Name().Short())
+	}
+	if b.Remote == "" || b.Remote == "." {
+		return b.Merge.Short(), nil
+	}
+	return b.Remote + "/" + b.Merge.Short(), nil
+}
+
+func (n Native) Dirty(ctx context.Context, dir string) (bool, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return false, err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return false, err
+	}
+	st, err := w.Status()
+	if err != nil {
+		return false, err
+	}
+	return !st.IsClean(), nil
+}
+
+func (n Native) Resolve(ctx context.Context, dir, rev string) (string, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return "", err
+	}
+	h, err := resolve(r, rev)
+	if err != nil {
+		return "", err
+	}
+	return h.String(), nil
+}
+
+func (n Native) Commit(ctx context.Context, dir, rev string) (Commit, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return Commit{}, err
+	}
+	h, err := resolve(r, rev)
+	if err != nil {
+		return Commit{}, err
+	}
+	c, err := r.CommitObject(h)
+	if err != nil {
+		return Commit{}, err
+	}
+	return Commit{Hash: h.String(), Author: c.Author.Name, Time: c.Committer.When}, nil
+}
+
+func (n Native) LastCommitBefore(ctx context.Context, dir, rev string, t time.Time) (string, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return "", err
+	}
+	h, err := resolve(r, rev)
+	if err != nil {
+		return "", err
+	}
+	c, err := r.CommitObject(h)
+	for err == nil {
+		if !c.Committer.When.After(t) {
+			return c.Hash.String(), nil
+		}
+		if c.NumParents() == 0 {
+			return "", nil
+		}
+		c, err = c.Parent(0)
+	}
+	return "", err
+}
+
+func (n Native) Checkout(ctx context.Context, dir, rev, branch string) error {
+	r, err := n.open(dir)
+	if err != nil {
+		return err
+	}
+	h, err := resolve(r, rev)
+	if err != nil {
+		return err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	o := &git.CheckoutOptions{Hash: h, Force: true}
+	if branch != "" {
+		name := plumbing.NewBranchReferenceName(branch)
+		if err := r.Storer.SetReference(plumbing.NewHashReference(name, h)); err != nil {
+			return err
+		}
+		if err := track(r, branch, rev); err != nil {
+			return err
+		}
+		o = &git.CheckoutOptions{Branch: name, Force: true}
+	}
+	if err := w.Checkout(o); err != nil {
+		return err
+	}
+	return clean(r, w.Filesystem)
+}
+
+// clean : removes everything in the worktree the index does not track,
+// ignored files and nested repositories included, like git clean -ffdx.
+// go-git's Worktree.Clean keeps ignored files and its forced checkout
+// keeps nested repositories, so the same mirror would differ from Exec's.
+func clean(r *git.Repository, fs billy.Filesystem) error {
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return err
+	}
+	// tracked files and submodules, and the directories holding them
+	tracked := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, e := range idx.Entries {
+		tracked[e.Name] = true
+		for d := path.Dir(e.Name); d != "."; d = path.Dir(d) {
+			dirs[d] = true
+		}
+	}
+	var sweep func(dir string) error
+	sweep = func(dir string) error {
+		infos, err := fs.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, fi := range infos {
+			name := path.Join(dir, fi.Name())
+			switch {
+			case dir == "" && fi.Name() == git.GitDirName, tracked[name]:
+				// kept; submodule checkouts are tracked as a whole
+			case fi.IsDir() && dirs[name]:
+				if err := sweep(name); err != nil {
+					return err
+				}
+			default:
+				if err := util.RemoveAll(fs, name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return sweep("")
+}
+
+func (n Native) RemoteURL(ctx context.Context, dir string) (string, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return "", err
+	}
+	origin, err := r.Remote("origin")
+	if err != nil {
+		return "", err
+	}
+	if urls := origin.Config().URLs; len(urls) > 0 {
+		return urls[0], nil
+	}
+	return "", fmt.Errorf("origin of %s has no URL", dir)
+}
+
+func (n Native) UpdateSubmodules(ctx context.Context, dir string, opts SubmoduleOptions) error {
+	r, err := n.open(dir)
+	if err != nil {
+		return err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	subs, err := w.Submodules()
+	if err != nil {
+		return err
+	}
+	o := &git.SubmoduleUpdateOptions{
+		Init:              true,
+		NoFetch:           opts.Offline,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+		Depth:             1,
+	}
+	if err := subs.UpdateContext(ctx, o); err != nil && !opts.Offline {
+		// shallow fetches cannot reach every pinned commit; retry in full
+		o.Depth = 0
+		return subs.UpdateContext(ctx, o)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (n Native) Submodules(ctx context.Context, dir string) ([]Submodule, error) {
+	r, err := n.open(dir)
+	if err != nil {
+		return nil, err
+	}
+	return submodules(r, "")
+}
+
+// submodules : submodules of r and, for checked out ones, their own
+func submodules(r *git.Repository, prefix string) ([]Submodule, error) {
+	w, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	subs, err := w.Submodules()
+	if err != nil {
+		return nil, err
+	}
+	var out []Submodule
+	for _, s := range subs {
+		st, err := s.Status()
+		if err != nil {
+			return nil, err
+		}
+		commit := st.Current
+		if commit.IsZero() {
+			commit = st.Expected
+		}
+		p := path.Join(prefix, s.Config().Path)
+		out = append(out, Submodule{Path: p, URL: s.Config().URL, Commit: commit.String()})
+		if st.Current.IsZero() {
+			continue
+		}
+		if sr, err := s.Repository(); err == nil {
+			nested, err := submodules(sr, p)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nested...)
+		}
+	}
+	return out, nil
+}
+
+// track : sets the upstream of branch when rev is a remote-tracking branch,
+// like git checkout -B does
+func track(r *git.Repository, branch, rev string) error {
+	remote, merge, ok := strings.Cut(strings.TrimPrefix(rev, "refs/remotes/"), "/")
+	if !ok || !strings.HasPrefix(rev, "refs/remotes/") {
+		return nil
+	}
+	cfg, err := r.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Branches[branch] = &gitconfig.Branch{Name: branch, Remote: remote, Merge: plumbing.NewBranchReferenceName(merge)}
+	return r.SetConfig(cfg)
+}
+
+// resolve : commit hash of rev; accepts anything ResolveRevision does
+// plus a trailing ^{commit}
+func resolve(r *git.Repository, rev string) (plumbing.Hash, error) {
+	h, err := r.ResolveRevision(plumbing.Revision(strings.TrimSuffix(rev, "^{commit}")))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("unknown revision %q", rev)
+	}
+	return *h, nil
+}
+
+// walk : calls fn for every commit reachable from h; missing parents of a
+// shallow history end the walk
+func walk(ctx context.Context, r *git.Repository, h plumbing.Hash, fn func(*object.Commit)) error {
+	log, err := r.Log(&git.LogOptions{From: h})
+	if err != nil {
+		return err
+	}
+	err = log.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fn(c)
+		return nil
+	})
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil
+	}
+	return err
+}
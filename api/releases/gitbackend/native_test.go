@@ -0,0 +1,261 @@
+package gitbackend
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	billy "github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// fixtureRepo : repository on disk with four commits on main, one day
+// apart, tagged v1.0 (lightweight) on the first and v1.1 (annotated) on
+// the third, and a branch "old" at the second
+func fixtureRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run := func(date string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+			"GIT_COMMITTER_NAME=Bob", "GIT_COMMITTER_EMAIL=bob@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("", "init", "-q", "-b", "main")
+	for i, date := range []string{"2024-01-01T12:00:00Z", "2024-01-02T12:00:00Z", "2024-01-03T12:00:00Z", "2024-01-04T12:00:00Z"} {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(date), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run(date, "add", "file.txt")
+		run(date, "commit", "-q", "-m", "commit "+date)
+		switch i {
+		case 0:
+			run(date, "tag", "v1.0")
+		case 1:
+			run(date, "branch", "old")
+		case 2:
+			run(date, "tag", "-a", "v1.1", "-m", "release 1.1")
+		}
+	}
+	return dir
+}
+
+func TestNativeMemory(t *testing.T) {
+	src := fixtureRepo(t)
+	ctx := context.Background()
+	b := NewMemory()
+
+	if _, err := b.Resolve(ctx, "clone", "HEAD"); !errors.Is(err, git.ErrRepositoryNotExists) {
+		t.Fatalf("Resolve before Clone: err = %v, want ErrRepositoryNotExists", err)
+	}
+	if err := b.Clone(ctx, "file://"+src, "clone", CloneOptions{}); err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	// nothing was written to disk
+	if _, err := os.Stat("clone"); !os.IsNotExist(err) {
+		t.Fatalf("Clone created ./clone on disk")
+	}
+
+	want, err := Exec{}.Resolve(ctx, src, "HEAD")
+	if err != nil {
+		t.Skipf("git rev-parse: %v", err)
+	}
+	if got, err := b.Resolve(ctx, "clone", "HEAD"); err != nil || got != want {
+		t.Errorf("Resolve(HEAD) = %q, %v; want %q", got, err, want)
+	}
+	if got, err := b.CurrentBranch(ctx, "clone"); err != nil || got != "main" {
+		t.Errorf("CurrentBranch = %q, %v; want main", got, err)
+	}
+	if got, err := b.RemoteURL(ctx, "clone"); err != nil || got != "file://"+src {
+		t.Errorf("RemoteURL = %q, %v", got, err)
+	}
+	if got, err := b.Describe(ctx, "clone", "HEAD"); err != nil || got != "v1.1-1-g"+want[:7] {
+		t.Errorf("Describe(HEAD) = %q, %v; want v1.1-1-g%s", got, err, want[:7])
+	}
+	if got, err := b.Describe(ctx, "clone", "v1.0"); err != nil || got != "v1.0" {
+		t.Errorf("Describe(v1.0) = %q, %v; want v1.0", got, err)
+	}
+	if got, err := b.RevListCount(ctx, "clone", "refs/remotes/origin/old", "HEAD"); err != nil || got != 2 {
+		t.Errorf("RevListCount(old..HEAD) = %d, %v; want 2", got, err)
+	}
+
+	c, err := b.Commit(ctx, "clone", "HEAD")
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if c.Hash != want || c.Author != "Alice" || !c.Time.Equal(time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Commit(HEAD) = %+v", c)
+	}
+	before, err := b.LastCommitBefore(ctx, "clone", "HEAD", time.Date(2024, 1, 2, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("LastCommitBefore: %v", err)
+	}
+	if old, _ := b.Resolve(ctx, "clone", "refs/remotes/origin/old"); before != old {
+		t.Errorf("LastCommitBefore(Jan 2 18:00) = %s, want %s (branch old)", before, old)
+	}
+
+	// detached checkout of a tag, then back onto a branch that tracks origin
+	if err := b.Checkout(ctx, "clone", "v1.0", ""); err != nil {
+		t.Fatalf("Checkout(v1.0): %v", err)
+	}
+	if got, _ := b.CurrentBranch(ctx, "clone"); got != "HEAD" {
+		t.Errorf("CurrentBranch after detached checkout = %q, want HEAD", got)
+	}
+	if err := b.Checkout(ctx, "clone", "refs/remotes/origin/old", "old"); err != nil {
+		t.Fatalf("Checkout(old): %v", err)
+	}
+	if got, err := b.Upstream(ctx, "clone"); err != nil || got != "origin/old" {
+		t.Errorf("Upstream = %q, %v; want origin/old", got, err)
+	}
+	if dirty, err := b.Dirty(ctx, "clone"); err != nil || dirty {
+		t.Errorf("Dirty = %t, %v; want clean", dirty, err)
+	}
+}
+
+// TestNativeStorage : any storer and worktree can back a repository
+func TestNativeStorage(t *testing.T) {
+	src := fixtureRepo(t)
+	ctx := context.Background()
+	s, fs := memory.NewStorage(), memfs.New()
+	var asked []string
+	b := Native{Storage: func(dir string) (storage.Storer, billy.Filesystem) {
+		asked = append(asked, dir)
+		return s, fs
+	}}
+
+	if err := b.Clone(ctx, src, "repo", CloneOptions{Branch: "old", Depth: 1}); err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if got, err := b.CurrentBranch(ctx, "repo"); err != nil || got != "old" {
+		t.Errorf("CurrentBranch = %q, %v; want old", got, err)
+	}
+	// the worktree is the billy filesystem that was passed in
+	data, err := fs.Open("file.txt")
+	if err != nil {
+		t.Fatalf("file.txt not in the worktree: %v", err)
+	}
+	data.Close()
+	if _, err := s.Reference("refs/heads/old"); err != nil {
+		t.Errorf("refs/heads/old not in the storer: %v", err)
+	}
+	for _, dir := range asked {
+		if dir != "repo" {
+			t.Errorf("Storage called with %q, want repo", dir)
+		}
+	}
+}
+
+// TestBackendsAgree : Exec and Native give the same answers on disk
+func TestBackendsAgree(t *testing.T) {
+	src := fixtureRepo(t)
+	ctx := context.Background()
+
+	for _, rev := range []string{"HEAD", "HEAD~1", "v1.1", "v1.0", "old"} {
+		e, eerr := Exec{}.Describe(ctx, src, rev)
+		n, nerr := Native{}.Describe(ctx, src, rev)
+		if eerr != nil || nerr != nil || e != n {
+			t.Errorf("Describe(%s): exec %q (%v), native %q (%v)", rev, e, eerr, n, nerr)
+		}
+	}
+	e, _ := Exec{}.RevListCount(ctx, src, "v1.0", "HEAD")
+	n, _ := Native{}.RevListCount(ctx, src, "v1.0", "HEAD")
+	if e != 3 || n != 3 {
+		t.Errorf("RevListCount(v1.0..HEAD): exec %d, native %d; want 3", e, n)
+	}
+	ec, _ := Exec{}.Commit(ctx, src, "old")
+	nc, _ := Native{}.Commit(ctx, src, "old")
+	if ec.Hash == "" || ec.Hash != nc.Hash || ec.Author != nc.Author || !ec.Time.Equal(nc.Time) {
+		t.Errorf("Commit(old): exec %+v, native %+v", ec, nc)
+	}
+
+	er, err := Exec{}.LsRemote(ctx, src)
+	if err != nil {
+		t.Fatalf("exec LsRemote: %v", err)
+	}
+	nr, err := Native{}.LsRemote(ctx, src)
+	if err != nil {
+		t.Fatalf("native LsRemote: %v", err)
+	}
+	hashes := func(refs []Ref) map[string]string {
+		m := make(map[string]string)
+		for _, r := range refs {
+			m[r.Name] = r.Hash
+		}
+		return m
+	}
+	eh, nh := hashes(er), hashes(nr)
+	for _, name := range []string{"HEAD", "refs/heads/main", "refs/heads/old", "refs/tags/v1.0", "refs/tags/v1.1", "refs/tags/v1.1^{}"} {
+		if eh[name] == "" || eh[name] != nh[name] {
+			t.Errorf("LsRemote %s: exec %q, native %q", name, eh[name], nh[name])
+		}
+	}
+}
+
+func TestCheckoutCleanAgrees(t *testing.T) {
+	src := fixtureRepo(t)
+	ctx := context.Background()
+
+	// files left in the worktree of a clone: ignored, untracked, a nested
+	// repository and a modified tracked file
+	worktree := func(b Backend) string {
+		dir := filepath.Join(t.TempDir(), "repo")
+		if err := (Exec{}).Clone(ctx, src, dir, CloneOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range map[string]string{
+			".gitignore":           "build/\n*.log\n",
+			"build/out.o":          "ignored",
+			"debug.log":            "ignored",
+			"notes.txt":            "untracked",
+			"tmp/a/b.txt":          "untracked",
+			"file.txt":             "modified",
+			"vendor/lib/.git/HEAD": "ref: refs/heads/main\n",
+			"vendor/lib/lib.c":     "nested repository",
+		} {
+			p := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := b.Checkout(ctx, dir, "v1.0", ""); err != nil {
+			t.Fatalf("%s Checkout: %v", b.Name(), err)
+		}
+		var files []string
+		filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			rel, _ := filepath.Rel(dir, p)
+			files = append(files, rel)
+			return nil
+		})
+		return strings.Join(files, " ")
+	}
+
+	e, n := worktree(Exec{}), worktree(Native{})
+	if e != n || e != ". file.txt" {
+		t.Errorf("worktree after Checkout: exec %q, native %q; want %q", e, n, ". file.txt")
+	}
+}
@@ -0,0 +1,157 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+)
+
+// Submodule : a submodule checked out in a mirror, pinned to Commit
+type Submodule = gitbackend.Submodule
+
+// lfsPointerPrefix : first line of every Git LFS pointer file
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1"
+
+// lfsPointerMax : pointer files are small; anything larger is real content
+const lfsPointerMax = 1024
+
+// syncSubmodules : initializes and updates submodules to the commits the
+// checkout pins, shallow when the server allows it. Offline, only objects
+// fetched earlier are used.
+func syncSubmodules(ctx context.Context, git gitbackend.Backend, dir string, opts Options) error {
+	if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); err != nil {
+		return nil
+	}
+	err := git.UpdateSubmodules(ctx, dir, gitbackend.SubmoduleOptions{Offline: opts.Offline, Progress: opts.Out})
+	if err != nil && opts.Offline {
+		return fmt.Errorf("submodules are not cached and offline mode is on: %v", err)
+	}
+	if err != nil {
+		return fmt.Errorf("submodule update failed: %v", err)
+	}
+	return nil
+}
+
+// Submodules : submodules of a checkout (recursively) and the commits
+// they are checked out at
+func Submodules(dir string) ([]Submodule, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); err != nil {
+		return nil, nil
+	}
+	subs, err := gitbackend.Default().Submodules(context.Background(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list submodules of %s: %v", dir, err)
+	}
+	return subs, nil
+}
+
+// usesLFS : whether any .gitattributes in the checkout routes files
+// through the LFS filter
+func usesLFS(dir string) bool {
+	found := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found {
+			return fs.SkipAll
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if d.Name() == ".gitattributes" {
+			if data, err := os.ReadFile(path); err == nil && bytes.Contains(data, []byte("filter=lfs")) {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// syncLFS : downloads Git LFS content when the checkout uses LFS, then
+// fails if any file is still only a pointer
+func syncLFS(dir string, opts Options) error {
+	if !usesLFS(dir) {
+		return nil
+	}
+	if _, err := exec.LookPath("git-lfs"); err == nil {
+		pull := []string{"lfs", "pull"}
+		if opts.Offline {
+			// offline: check out objects already in the local LFS store
+			pull = []string{"lfs", "checkout"}
+		}
+		if err := runGit(opts, dir, pull...); err != nil {
+			fmt.Fprintf(opts.warn(), "⚠️ git lfs failed in %s: %v\n", dir, err)
+		}
+		if err := runGit(opts, dir, append([]string{"submodule", "foreach", "--quiet", "--recursive", "git"}, pull...)...); err != nil {
+			fmt.Fprintf(opts.warn(), "⚠️ git lfs failed in submodules of %s: %v\n", dir, err)
+		}
+	}
+
+	pointers, err := LFSPointers(dir)
+	if err != nil {
+		return err
+	}
+	if len(pointers) == 0 {
+		return nil
+	}
+	hint := "install git-lfs and run the command again"
+	if _, err := exec.LookPath("git-lfs"); err == nil {
+		hint = "the LFS server did not provide them"
+		if opts.Offline {
+			hint = "they were never downloaded; run again without --offline"
+		}
+	}
+	return fmt.Errorf("%d files are Git LFS pointers without content (%s): %s",
+		len(pointers), strings.Join(firstN(pointers, 3), ", "), hint)
+}
+
+// LFSPointers : files in the checkout that still hold a Git LFS pointer
+// instead of their content, relative to dir
+func LFSPointers(dir string) ([]string, error) {
+	var pointers []string
+	prefix := make([]byte, len(lfsPointerPrefix))
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > lfsPointerMax || info.Size() < int64(len(prefix)) {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		_, err = io.ReadFull(f, prefix)
+		f.Close()
+		if err == nil && string(prefix) == lfsPointerPrefix {
+			rel, _ := filepath.Rel(dir, path)
+			pointers = append(pointers, rel)
+		}
+		return nil
+	})
+	return pointers, err
+}
+
+func firstN(s []string, n int) []string {
+	if len(s) > n {
+		return append(s[:n:n], "…")
+	}
+	return s
+}
@@ -0,0 +1,305 @@
+// Package mirror : persistent git checkouts of registry repositories under
+// $XDG_CACHE_HOME/hypr-release/repos/<name>, shared by install, update and
+// check. Checkouts are refreshed with incremental fetches and keep working
+// offline from the last successful fetch.
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+// Options : how Sync talks to the remote
+type Options struct {
+	// Offline : never fetch; use what the last fetch left behind
+	Offline bool
+	// Out : receives git progress output and warnings; nil discards the
+	// progress and sends warnings to stderr
+	Out io.Writer
+	// At : when set, check out the last commit on ref made before this time
+	At time.Time
+}
+
+// warn : writer for warnings that must not be discarded with the progress
+func (o Options) warn() io.Writer {
+	if o.Out == nil {
+		return os.Stderr
+	}
+	return o.Out
+}
+
+// Mirror : one cached repository
+type Mirror struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Remote    string    `json:"remote"`
+	Ref       string    `json:"ref"`
+	Commit    string    `json:"commit"`
+	FetchedAt time.Time `json:"fetched_at"`
+	SizeBytes int64     `json:"size_bytes"`
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._+-]`)
+
+// Dir : directory holding every mirror
+func Dir() string {
+	return filepath.Join(paths.CacheDir(), "repos")
+}
+
+// Path : checkout directory of the mirror for a registry entry name
+func Path(name string) string {
+	return filepath.Join(Dir(), unsafeName.ReplaceAllString(name, "_"))
+}
+
+// Sync : brings the mirror of d up to date and checks out ref (a branch,
+// tag or commit; empty means d.Branch), or with opts.At the last commit of
+// ref before that time. The first call clones; later calls fetch only what
+// changed. When fetching fails, the previously fetched state is used if it
+// has ref. Submodules are updated to their pinned commits and Git LFS
+// content is downloaded; files left as LFS pointers are an error. Returns
+// the checkout path; Head gives the checked out commit.
+func Sync(d summaryofversion.Dotfile, ref string, opts Options) (string, error) {
+	if ref == "" {
+		ref = d.Branch
+	}
+	dir := Path(d.Name)
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return "", err
+	}
+	unlock, err := lock(dir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	ctx, git := context.Background(), gitbackend.Default()
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if opts.Offline {
+			return "", fmt.Errorf("no cached copy of %s and offline mode is on", d.Name)
+		}
+		os.RemoveAll(dir)
+		if err := git.Clone(ctx, d.Repo, dir, gitbackend.CloneOptions{NoCheckout: true, Progress: opts.Out}); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to clone %s: %v", d.Repo, err)
+		}
+	} else if !opts.Offline {
+		if err := git.Fetch(ctx, dir, gitbackend.FetchOptions{URL: d.Repo, Progress: opts.Out}); err != nil {
+			fmt.Fprintf(opts.warn(), "⚠️ fetch of %s failed, using the copy from %s: %v\n", d.Name, fetchedAt(dir).Format("2006-01-02 15:04"), err)
+		}
+	}
+
+	if !opts.At.IsZero() {
+		sha, err := commitBefore(ctx, git, dir, ref, opts.At)
+		if err != nil {
+			return "", err
+		}
+		ref = sha
+	}
+	if err := checkout(ctx, git, dir, ref); err != nil {
+		return "", err
+	}
+	if err := syncSubmodules(ctx, git, dir, opts); err != nil {
+		return "", err
+	}
+	if err := syncLFS(dir, opts); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Fetch : fetches branches and tags of d into its existing mirror without
+// touching the checkout, so whatever was installed stays checked out
+func Fetch(d summaryofversion.Dotfile, opts Options) error {
+	dir := Path(d.Name)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return fmt.Errorf("no cached copy of %s", d.Name)
+	}
+	if opts.Offline {
+		return nil
+	}
+	unlock, err := lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return gitbackend.Default().Fetch(context.Background(), dir, gitbackend.FetchOptions{URL: d.Repo, Progress: opts.Out})
+}
+
+// Resolve : commit ref (empty means d.Branch) names in the existing mirror
+// of d, as last fetched; with at, the last commit of ref before it. Nothing
+// is fetched or checked out, so dry runs can use it.
+func Resolve(d summaryofversion.Dotfile, ref string, at time.Time) (string, error) {
+	if ref == "" {
+		ref = d.Branch
+	}
+	dir := Path(d.Name)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return "", fmt.Errorf("no cached copy of %s", d.Name)
+	}
+	ctx, git := context.Background(), gitbackend.Default()
+	if !at.IsZero() {
+		return commitBefore(ctx, git, dir, ref, at)
+	}
+	for _, name := range []string{"refs/remotes/origin/" + ref, ref} {
+		if sha, err := git.Resolve(ctx, dir, name); err == nil {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("ref %q not found in %s", ref, dir)
+}
+
+// Head : commit checked out in a mirror (or any git worktree)
+func Head(dir string) (string, error) {
+	return gitbackend.Default().Resolve(context.Background(), dir, "HEAD")
+}
+
+// commitBefore : last commit on ref's first-parent history committed
+// before t
+func commitBefore(ctx context.Context, git gitbackend.Backend, dir, ref string, t time.Time) (string, error) {
+	base := ref
+	if _, err := git.Resolve(ctx, dir, "refs/remotes/origin/"+ref); err == nil {
+		base = "refs/remotes/origin/" + ref
+	}
+	sha, err := git.LastCommitBefore(ctx, dir, base, t)
+	if err != nil {
+		return "", fmt.Errorf("ref %q not found in %s", ref, dir)
+	}
+	if sha == "" {
+		return "", fmt.Errorf("no commit on %s before %s", ref, t.Format("2006-01-02 15:04"))
+	}
+	return sha, nil
+}
+
+// checkout : forces the worktree to ref; branches track origin/<branch>
+func checkout(ctx context.Context, git gitbackend.Backend, dir, ref string) error {
+	if _, err := git.Resolve(ctx, dir, "refs/remotes/origin/"+ref); err == nil {
+		return git.Checkout(ctx, dir, "refs/remotes/origin/"+ref, ref)
+	}
+	if _, err := git.Resolve(ctx, dir, ref); err == nil {
+		return git.Checkout(ctx, dir, ref, "")
+	}
+	return fmt.Errorf("ref %q not found in %s", ref, dir)
+}
+
+// Get : the mirror for name, if one exists
+func Get(name string) (*Mirror, error) {
+	dir := Path(name)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil, fmt.Errorf("no cached copy of %s", name)
+	}
+	ctx, git := context.Background(), gitbackend.Default()
+	m := &Mirror{Name: name, Path: dir, FetchedAt: fetchedAt(dir)}
+	m.Remote, _ = git.RemoteURL(ctx, dir)
+	m.Commit, _ = git.Resolve(ctx, dir, "HEAD")
+	if m.Ref, _ = git.CurrentBranch(ctx, dir); m.Ref == "" || m.Ref == "HEAD" {
+		m.Ref = "(detached)"
+	}
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			m.SizeBytes += info.Size()
+		}
+		return nil
+	})
+	return m, nil
+}
+
+// List : every cached mirror, sorted by name
+func List() ([]Mirror, error) {
+	entries, err := os.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Mirror
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if m, err := Get(e.Name()); err == nil {
+			out = append(out, *m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Remove : deletes the mirror for name
+func Remove(name string) error {
+	dir := Path(name)
+	unlock, err := lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	os.Remove(dir + ".lock")
+	return nil
+}
+
+// fetchedAt : time of the last fetch (or of the clone)
+func fetchedAt(dir string) time.Time {
+	for _, f := range []string{"FETCH_HEAD", "HEAD"} {
+		if info, err := os.Stat(filepath.Join(dir, ".git", f)); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
+
+// lock : exclusive lock on the mirror so concurrent runs do not fetch into
+// the same checkout
+func lock(dir string) (func(), error) {
+	f, err := os.OpenFile(dir+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot lock %s: %v", dir, err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// runGit : runs the git binary; only Git LFS, which the native backend
+// cannot do, still needs it
+func runGit(opts Options, dir string, args ...string) error {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr strings.Builder
+	if opts.Out != nil {
+		cmd.Stdout = opts.Out
+		cmd.Stderr = io.MultiWriter(opts.Out, &stderr)
+	} else {
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s", strings.SplitN(msg, "\n", 2)[0])
+		}
+		return err
+	}
+	return nil
+}
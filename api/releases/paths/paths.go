@@ -0,0 +1,41 @@
+// Package paths : XDG base directories used by hypr-release.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const appName = "hypr-release"
+
+// ConfigDir : $XDG_CONFIG_HOME/hypr-release (user configuration)
+func ConfigDir() string {
+	return filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), appName)
+}
+
+// StateDir : $XDG_STATE_HOME/hypr-release (transactions, manifests)
+func StateDir() string {
+	return filepath.Join(xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state")), appName)
+}
+
+// CacheDir : $XDG_CACHE_HOME/hypr-release (re-creatable data)
+func CacheDir() string {
+	return filepath.Join(xdgDir("XDG_CACHE_HOME", ".cache"), appName)
+}
+
+// HyprConfigDir : Hyprland's own configuration directory, where dotfiles are installed
+func HyprConfigDir() string {
+	return filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), "hypr")
+}
+
+// xdgDir : value of env if it is an absolute path, otherwise ~/fallback
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, fallback)
+}
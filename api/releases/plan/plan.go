@@ -0,0 +1,170 @@
+// Package plan : what a mutating operation would do. Install, update and
+// metadata writers fill a Plan instead of touching the machine when they
+// run in dry-run mode, so the plan can be reviewed before a rollout.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Action : what happens to a file
+type Action string
+
+const (
+	Create    Action = "create"
+	Overwrite Action = "overwrite"
+	Delete    Action = "delete"
+)
+
+// Command origins
+const (
+	OriginGit    = "git"
+	OriginScript = "script"
+	OriginRegex  = "regex"
+)
+
+// File : a file the operation would write or delete
+type File struct {
+	Path   string `json:"path"`
+	Action Action `json:"action"`
+	Source string `json:"source,omitempty"`
+}
+
+// Command : a command the operation would run
+type Command struct {
+	Origin  string `json:"origin"`
+	Dir     string `json:"dir,omitempty"`
+	Command string `json:"command"`
+}
+
+// Metadata : a metadata file the operation would write and the keys it sets
+type Metadata struct {
+	Path   string   `json:"path"`
+	Action Action   `json:"action"`
+	Keys   []string `json:"keys,omitempty"`
+}
+
+// Plan : everything an operation would do, in order
+type Plan struct {
+	Operation string     `json:"operation"`
+	Target    string     `json:"target"`
+	Files     []File     `json:"files,omitempty"`
+	Commands  []Command  `json:"commands,omitempty"`
+	Metadata  []Metadata `json:"metadata,omitempty"`
+	Notes     []string   `json:"notes,omitempty"`
+}
+
+// New : empty plan for operation on target (e.g. "install", "HyDE")
+func New(operation, target string) *Plan {
+	return &Plan{Operation: operation, Target: target}
+}
+
+// AddFile : path would be written from source; create or overwrite
+// depending on what is on disk now
+func (p *Plan) AddFile(path, source string) {
+	p.Files = append(p.Files, File{Path: path, Action: actionFor(path), Source: source})
+}
+
+// AddDelete : path would be removed
+func (p *Plan) AddDelete(path string) {
+	p.Files = append(p.Files, File{Path: path, Action: Delete})
+}
+
+// AddCommand : command would run in dir
+func (p *Plan) AddCommand(origin, dir, command string) {
+	p.Commands = append(p.Commands, Command{Origin: origin, Dir: dir, Command: command})
+}
+
+// AddMetadata : metadata file path would be written with keys
+func (p *Plan) AddMetadata(path string, keys []string) {
+	p.Metadata = append(p.Metadata, Metadata{Path: path, Action: actionFor(path), Keys: keys})
+}
+
+// Note : free-form remark, e.g. a fallback that would apply on failure
+func (p *Plan) Note(format string, a ...any) {
+	p.Notes = append(p.Notes, fmt.Sprintf(format, a...))
+}
+
+// Empty : true when the plan would change nothing
+func (p *Plan) Empty() bool {
+	return len(p.Files) == 0 && len(p.Commands) == 0 && len(p.Metadata) == 0
+}
+
+// Text : human-readable plan
+func (p *Plan) Text() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📝 Plan: %s %s\n", p.Operation, p.Target))
+	if p.Empty() {
+		b.WriteString("  (no changes)\n")
+	}
+	if len(p.Commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		for _, c := range p.Commands {
+			b.WriteString(fmt.Sprintf("  [%s] %s", c.Origin, c.Command))
+			if c.Dir != "" {
+				b.WriteString(fmt.Sprintf("  (in %s)", c.Dir))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(p.Files) > 0 {
+		b.WriteString("\nFiles:\n")
+		for _, f := range p.Files {
+			b.WriteString(fmt.Sprintf("  %-9s %s", f.Action, f.Path))
+			if f.Source != "" {
+				b.WriteString(fmt.Sprintf("  ← %s", f.Source))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(p.Metadata) > 0 {
+		b.WriteString("\nMetadata:\n")
+		for _, m := range p.Metadata {
+			b.WriteString(fmt.Sprintf("  %-9s %s\n", m.Action, m.Path))
+			if len(m.Keys) > 0 {
+				b.WriteString(fmt.Sprintf("            keys: %s\n", strings.Join(m.Keys, ", ")))
+			}
+		}
+	}
+	if len(p.Notes) > 0 {
+		b.WriteString("\nNotes:\n")
+		for _, n := range p.Notes {
+			b.WriteString(fmt.Sprintf("  - %s\n", n))
+		}
+	}
+	return b.String()
+}
+
+// JSON : machine-readable plan
+func (p *Plan) JSON() (string, error) {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode plan: %v", err)
+	}
+	return string(out), nil
+}
+
+// KeysOf : KEY names assigned in a KEY="value" metadata file body
+func KeysOf(content string) []string {
+	var keys []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, "="); i > 0 {
+			keys = append(keys, line[:i])
+		}
+	}
+	return keys
+}
+
+func actionFor(path string) Action {
+	if _, err := os.Lstat(path); err == nil {
+		return Overwrite
+	}
+	return Create
+}
@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Answers : answers questions from a prepared KEY=value file. Questions
+// missing from the file go to Fallback, or fail when Fallback is nil.
+//
+//	# answers for an unattended install
+//	install.ref="v2.1"
+//	install.readme.proceed="yes"
+type Answers struct {
+	Values   map[string]string
+	Fallback Prompter
+}
+
+// LoadAnswers : reads an answers file in the same KEY="value" format as
+// the hyprland-release metadata files
+func LoadAnswers(path string) (*Answers, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open answers file: %v", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, n)
+		}
+		values[strings.TrimSpace(parts[0])] = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return &Answers{Values: values}, nil
+}
+
+func (a *Answers) Confirm(key, question string, def bool) (bool, error) {
+	v, ok := a.Values[key]
+	if !ok {
+		if a.Fallback != nil {
+			return a.Fallback.Confirm(key, question, def)
+		}
+		return false, &AnswerError{Key: key, Err: ErrNoAnswer}
+	}
+	switch strings.ToLower(v) {
+	case "":
+		return def, nil
+	case "y", "yes", "true", "1":
+		return true, nil
+	case "n", "no", "false", "0":
+		return false, nil
+	}
+	return false, &AnswerError{Key: key, Err: fmt.Errorf("invalid yes/no answer %q", v)}
+}
+
+func (a *Answers) Input(key, question, def string) (string, error) {
+	v, ok := a.Values[key]
+	if !ok {
+		if a.Fallback != nil {
+			return a.Fallback.Input(key, question, def)
+		}
+		return "", &AnswerError{Key: key, Err: ErrNoAnswer}
+	}
+	if v == "" {
+		return def, nil
+	}
+	return v, nil
+}
+
+// Select accepts the 1-based option number, the option text, or the
+// first word of the option text (e.g. a model file name).
+func (a *Answers) Select(key, question string, options []string) (int, error) {
+	v, ok := a.Values[key]
+	if !ok {
+		if a.Fallback != nil {
+			return a.Fallback.Select(key, question, options)
+		}
+		return -1, &AnswerError{Key: key, Err: ErrNoAnswer}
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= len(options) {
+		return n - 1, nil
+	}
+	for i, o := range options {
+		if strings.EqualFold(o, v) || strings.EqualFold(firstWord(o), v) {
+			return i, nil
+		}
+	}
+	return -1, &AnswerError{Key: key, Err: fmt.Errorf("answer %q matches no option", v)}
+}
+
+func firstWord(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+// Package prompt : user interaction used by install, update and model
+// selection, so the same code path can run from a terminal, the GUI or
+// unattended in CI.
+package prompt
+
+import "errors"
+
+// Prompter : answers every confirmation and choice made by an operation.
+// key is a stable identifier for the question (see the Prompt* constants
+// in updateing) and is what answers files are keyed on.
+type Prompter interface {
+	// Confirm asks a yes/no question; def is used for an empty answer.
+	Confirm(key, question string, def bool) (bool, error)
+	// Input asks for free text; def is used for an empty answer.
+	Input(key, question, def string) (string, error)
+	// Select asks for one of options and returns its index.
+	Select(key, question string, options []string) (int, error)
+}
+
+var (
+	// ErrNoAnswer : the prompter has no answer for a question
+	ErrNoAnswer = errors.New("no answer available")
+	// ErrCanceled : the user dismissed the question
+	ErrCanceled = errors.New("canceled by user")
+)
+
+// AssumeYes : confirms everything and keeps every default, for unattended
+// runs. Choices without a default cannot be answered.
+type AssumeYes struct{}
+
+func (AssumeYes) Confirm(key, question string, def bool) (bool, error) {
+	return true, nil
+}
+
+func (AssumeYes) Input(key, question, def string) (string, error) {
+	return def, nil
+}
+
+func (AssumeYes) Select(key, question string, options []string) (int, error) {
+	return -1, &AnswerError{Key: key, Err: ErrNoAnswer}
+}
+
+// AnswerError : a question that could not be answered
+type AnswerError struct {
+	Key string
+	Err error
+}
+
+func (e *AnswerError) Error() string {
+	return e.Key + ": " + e.Err.Error()
+}
+
+func (e *AnswerError) Unwrap() error {
+	return e.Err
+}
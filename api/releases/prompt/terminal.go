@@ -0,0 +1,92 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Terminal : asks questions on a line-oriented terminal
+type Terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewTerminal : terminal prompter reading answers from in and writing questions to out
+func NewTerminal(in io.Reader, out io.Writer) *Terminal {
+	return &Terminal{in: bufio.NewReader(in), out: out}
+}
+
+// Stdio : terminal prompter on os.Stdin / os.Stdout
+func Stdio() *Terminal {
+	return NewTerminal(os.Stdin, os.Stdout)
+}
+
+func (t *Terminal) Confirm(key, question string, def bool) (bool, error) {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	for {
+		fmt.Fprintf(t.out, "%s %s: ", question, hint)
+		line, err := t.readLine(key)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(line) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(t.out, "Please answer y or n.")
+	}
+}
+
+func (t *Terminal) Input(key, question, def string) (string, error) {
+	fmt.Fprintf(t.out, "%s: ", question)
+	line, err := t.readLine(key)
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+func (t *Terminal) Select(key, question string, options []string) (int, error) {
+	for i, o := range options {
+		fmt.Fprintf(t.out, "[%d] %s\n", i+1, o)
+	}
+	for {
+		fmt.Fprintf(t.out, "%s: ", question)
+		line, err := t.readLine(key)
+		if err != nil {
+			return -1, err
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(t.out, "Please enter a number between 1 and %d.\n", len(options))
+	}
+}
+
+// readLine : reads one trimmed line; EOF before any input means there is
+// nobody to answer, so the question fails instead of blocking forever.
+func (t *Terminal) readLine(key string) (string, error) {
+	line, err := t.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			fmt.Fprintln(t.out)
+			return "", &AnswerError{Key: key, Err: ErrNoAnswer}
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
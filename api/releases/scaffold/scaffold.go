@@ -0,0 +1,310 @@
+// Package scaffold proposes registry entries by inspecting git repositories.
+package scaffold
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/check"
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+	"github.com/hyprcommunity/hypr-release/api/releases/updateing"
+)
+
+// PromptAddConfirm : answers-file key of the "write this entry?" question
+const PromptAddConfirm = "registry.add.confirm"
+
+// Proposal : registry entry proposed for a repository, with the evidence
+// each field was derived from
+type Proposal struct {
+	Entry summaryofversion.Dotfile `json:"entry"`
+	// GitTags : tags found in the repository, newest first when known
+	GitTags []string `json:"git_tags,omitempty"`
+	// Notes : how the fields were derived, for the user to review
+	Notes []string `json:"notes,omitempty"`
+}
+
+// extraInstallers : installer scripts besides updateing.InstallerScripts
+// that are worth proposing as the entry's installer field
+var extraInstallers = []string{"setup.sh", "install", "scripts/install.sh", "scripts/setup.sh"}
+
+// genericNames : repository names too vague to be used as entry names alone
+var genericNames = map[string]bool{
+	"dotfiles": true, "dots": true, "hyprland": true, "hyprland-dots": true,
+	"hyprland-dotfiles": true, "dots-hyprland": true, "config": true, "configs": true,
+}
+
+var (
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._+-]+`)
+	markdownLink     = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownMarks    = regexp.MustCompile("[*_`~]+")
+	htmlTag          = regexp.MustCompile(`<[^>]+>`)
+)
+
+// Inspect : examines a local checkout or a remote git URL and proposes a
+// registry entry. Remote repositories are shallow-cloned into a temporary
+// directory that is removed afterwards.
+func Inspect(repo string) (*Proposal, error) {
+	p := &Proposal{}
+	var workdir string
+
+	ctx, gb := context.Background(), gitbackend.Default()
+	if info, err := os.Stat(repo); err == nil && info.IsDir() {
+		abs, err := filepath.Abs(repo)
+		if err != nil {
+			return nil, err
+		}
+		refs, err := gb.LsRemote(ctx, abs)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a git repository", repo)
+		}
+		workdir = abs
+		if origin, err := gb.RemoteURL(ctx, abs); err == nil && origin != "" {
+			p.Entry.Repo = origin
+			p.note("repo: origin remote of %s", abs)
+		} else {
+			p.Entry.Repo = "file://" + abs
+			p.note("repo: local path (no origin remote)")
+		}
+		p.Entry.Branch = localDefaultBranch(refs)
+		p.GitTags = tagNames(ctx, abs)
+	} else {
+		p.Entry.Repo = repo
+		forge := check.NewGitForge(repo)
+		r, err := forge.Repo(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot reach %s: %v", repo, err)
+		}
+		branch := r.DefaultBranch
+		p.Entry.Branch = branch
+		if branch != "" {
+			p.note("branch: remote HEAD")
+		}
+		p.GitTags = tagNames(ctx, repo)
+
+		tmp, err := os.MkdirTemp("", "hyprrelease-scaffold-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		workdir = filepath.Join(tmp, "repo")
+		if err := gb.Clone(ctx, repo, workdir, gitbackend.CloneOptions{Branch: branch, Depth: 1}); err != nil {
+			return nil, fmt.Errorf("failed to clone %s: %v", repo, err)
+		}
+	}
+	if p.Entry.Branch == "" {
+		p.Entry.Branch = summaryofversion.DefaultBranch
+		p.note("branch: not detected, using %s", summaryofversion.DefaultBranch)
+	}
+
+	owner, repoName := urlOwner(p.Entry.Repo)
+	if owner != "" {
+		p.Entry.Author = owner
+		p.note("author: repository owner in the URL")
+	} else if c, err := gb.Commit(ctx, workdir, "HEAD"); err == nil && c.Author != "" {
+		p.Entry.Author = c.Author
+		p.note("author: last commit author")
+	}
+	if repoName == "" {
+		repoName = filepath.Base(strings.TrimSuffix(strings.TrimPrefix(p.Entry.Repo, "file://"), "/"))
+	}
+	p.Entry.Name = entryName(repoName, p.Entry.Author)
+
+	p.Entry.HasReleases = p.detectReleases()
+
+	if s := findInstaller(workdir); s != "" {
+		p.note("installer: %s", s)
+		if !isDefaultInstaller(s) {
+			p.Entry.Installer = s
+		}
+	} else {
+		p.note("installer: none found; install will fall back to the README and file copy")
+	}
+
+	if desc := readmeDescription(workdir); desc != "" {
+		p.Entry.Description = desc
+		p.note("description: first paragraph of the README")
+	}
+
+	if err := summaryofversion.Validate(p.Entry); err != nil {
+		return p, fmt.Errorf("proposed entry is invalid: %v", err)
+	}
+	return p, nil
+}
+
+func (p *Proposal) note(format string, args ...any) {
+	p.Notes = append(p.Notes, fmt.Sprintf(format, args...))
+}
+
+// detectReleases : forge releases through its API when possible, tags otherwise
+func (p *Proposal) detectReleases() bool {
+	if forge, err := check.ForgeFor(p.Entry.Repo, p.Entry.Forge); err == nil {
+		releases, err := forge.Releases(context.Background(), 1)
+		if err == nil {
+			p.note("has_releases: %s releases", check.ForgeName(forge.Kind()))
+			return len(releases) > 0
+		}
+	}
+	if len(p.GitTags) > 0 {
+		p.note("has_releases: %d git tags found (latest %s)", len(p.GitTags), p.GitTags[0])
+		return true
+	}
+	p.note("has_releases: no tags found")
+	return false
+}
+
+// tagNames : tags of the repository, newest version first
+func tagNames(ctx context.Context, repo string) []string {
+	tags, err := check.NewGitForge(repo).Tags(ctx, 0)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// localDefaultBranch : branch origin/HEAD points to, otherwise the checked
+// out branch
+func localDefaultBranch(refs []gitbackend.Ref) string {
+	var head string
+	for _, r := range refs {
+		switch r.Name {
+		case "refs/remotes/origin/HEAD":
+			if b, ok := strings.CutPrefix(r.Target, "refs/remotes/origin/"); ok {
+				return b
+			}
+		case "HEAD":
+			head = strings.TrimPrefix(r.Target, "refs/heads/")
+		}
+	}
+	return head
+}
+
+// urlOwner : owner and repository name of forge-style URLs
+// (https://host/owner/name, user@host:owner/name)
+func urlOwner(repo string) (owner, name string) {
+	var p string
+	if u, err := url.Parse(repo); err == nil && u.Scheme != "" && u.Scheme != "file" {
+		p = u.Path
+	} else if _, rest, ok := strings.Cut(repo, ":"); ok && !strings.HasPrefix(repo, "file:") {
+		p = rest
+	} else {
+		return "", ""
+	}
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	name = strings.TrimSuffix(parts[len(parts)-1], ".git")
+	if len(parts) < 2 {
+		return "", name
+	}
+	return parts[len(parts)-2], name
+}
+
+// entryName : registry name for a repository; vague names get the author
+// as prefix (dotfiles by foo → foo-dotfiles)
+func entryName(repoName, author string) string {
+	name := strings.TrimSuffix(repoName, ".git")
+	if genericNames[strings.ToLower(name)] && author != "" {
+		name = author + "-" + name
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-._+")
+	if name == "" {
+		name = "dotfiles"
+	}
+	return name
+}
+
+func findInstaller(dir string) string {
+	for _, s := range append(append([]string{}, updateing.InstallerScripts...), extraInstallers...) {
+		if info, err := os.Stat(filepath.Join(dir, s)); err == nil && !info.IsDir() {
+			return s
+		}
+	}
+	return ""
+}
+
+func isDefaultInstaller(s string) bool {
+	for _, d := range updateing.InstallerScripts {
+		if s == d {
+			return true
+		}
+	}
+	return false
+}
+
+// readmeDescription : first prose paragraph of the README, without
+// headings, badges, HTML and markdown markup; cut to one or two sentences
+func readmeDescription(dir string) string {
+	var f *os.File
+	for _, name := range []string{"README.md", "README", "readme.md", "readme", "README.rst", "README.txt"} {
+		if file, err := os.Open(filepath.Join(dir, name)); err == nil {
+			f = file
+			break
+		}
+	}
+	if f == nil {
+		return ""
+	}
+	defer f.Close()
+
+	var para []string
+	inCode := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		text := htmlTag.ReplaceAllString(line, "")
+		text = markdownLink.ReplaceAllString(text, "$1")
+		text = strings.TrimSpace(markdownMarks.ReplaceAllString(text, ""))
+		skip := text == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "|") ||
+			strings.HasPrefix(line, ">") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "===") ||
+			strings.HasPrefix(line, "![") || strings.HasPrefix(line, "[![") || !hasLetters(text)
+		if skip {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		para = append(para, text)
+	}
+	return shorten(strings.Join(para, " "), 160)
+}
+
+func hasLetters(s string) bool {
+	n := 0
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' {
+			n++
+		}
+	}
+	return n >= 3
+}
+
+// shorten : whole sentences up to max characters, or a cut with "…"
+func shorten(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if i := strings.LastIndex(s[:max], ". "); i > 20 {
+		return s[:i+1]
+	}
+	cut := s[:max]
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,281 @@
+package scaffold
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/check"
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+	"github.com/hyprcommunity/hypr-release/api/releases/updateing"
+)
+
+// Severity : how serious a registry health issue is
+type Severity string
+
+const (
+	// SeverityError : installing the entry will fail or do the wrong thing
+	SeverityError Severity = "error"
+	// SeverityWarning : the entry works but its metadata is stale or doubtful
+	SeverityWarning Severity = "warning"
+)
+
+// Issue : one finding about a registry entry
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+}
+
+// EntryReport : health of one registry entry
+type EntryReport struct {
+	Name       string     `json:"name"`
+	Repo       string     `json:"repo"`
+	Branch     string     `json:"branch"`
+	Source     string     `json:"source,omitempty"`
+	LastCommit *time.Time `json:"last_commit,omitempty"`
+	Archived   bool       `json:"archived,omitempty"`
+	Issues     []Issue    `json:"issues,omitempty"`
+}
+
+// Report : result of ValidateRegistry
+type Report struct {
+	Checked  time.Time     `json:"checked"`
+	Entries  []EntryReport `json:"entries"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+}
+
+// ValidateOptions : registry health check settings
+type ValidateOptions struct {
+	// MaxAge : last commits older than this are reported; 0 means one year
+	MaxAge time.Duration
+	// Timeout : limit for each git operation; 0 means one minute
+	Timeout time.Duration
+	// Workers : entries checked in parallel; 0 means 4
+	Workers int
+}
+
+func (o ValidateOptions) maxAge() time.Duration {
+	if o.MaxAge <= 0 {
+		return 365 * 24 * time.Hour
+	}
+	return o.MaxAge
+}
+
+func (o ValidateOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return time.Minute
+	}
+	return o.Timeout
+}
+
+// ValidateRegistry : checks every entry and returns the reports in input order
+func ValidateRegistry(entries []summaryofversion.Dotfile, opts ValidateOptions) Report {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	r := Report{Checked: time.Now(), Entries: make([]EntryReport, len(entries))}
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r.Entries[i] = ValidateEntry(entries[i], opts)
+			}
+		}()
+	}
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, e := range r.Entries {
+		for _, is := range e.Issues {
+			if is.Severity == SeverityError {
+				r.Errors++
+			} else {
+				r.Warnings++
+			}
+		}
+	}
+	return r
+}
+
+// ValidateEntry : checks that the repository is reachable, the branch
+// exists, HasReleases matches the repository, an installer or README is
+// present, and that the repository is neither stale nor archived
+func ValidateEntry(d summaryofversion.Dotfile, opts ValidateOptions) EntryReport {
+	e := EntryReport{Name: d.Name, Repo: d.Repo, Branch: d.Branch, Source: d.Source}
+	if err := summaryofversion.Validate(d); err != nil {
+		e.add(SeverityError, "schema", "%v", err)
+		return e
+	}
+	if _, err := summaryofversion.Successor(d); err != nil {
+		e.add(SeverityError, "replaced_by", "%v", err)
+	}
+	// a moved entry is checked at its new location
+	d, _ = d.Moved()
+	e.Repo = d.Repo
+
+	// reachability and branch
+	refs, err := lsRemote(opts, d.Repo)
+	if err != nil {
+		e.add(SeverityError, "reachable", "repository is not reachable: %v", err)
+		return e
+	}
+	if _, ok := refs["refs/heads/"+d.Branch]; !ok {
+		if _, isTag := refs["refs/tags/"+d.Branch]; isTag {
+			e.add(SeverityWarning, "branch", "%q is a tag, not a branch", d.Branch)
+		} else {
+			msg := fmt.Sprintf("branch %q does not exist", d.Branch)
+			if head := refs["HEAD-symref"]; head != "" {
+				msg += fmt.Sprintf(" (default branch is %q)", strings.TrimPrefix(head, "refs/heads/"))
+			}
+			e.add(SeverityError, "branch", "%s", msg)
+			return e
+		}
+	}
+
+	// releases
+	var tags []string
+	for r := range refs {
+		if t, ok := strings.CutPrefix(r, "refs/tags/"); ok && !strings.HasSuffix(t, "^{}") {
+			tags = append(tags, t)
+		}
+	}
+	forge, err := check.ForgeFor(d.Repo, d.Forge)
+	if err != nil {
+		e.add(SeverityWarning, "forge", "%v", err)
+		forge = check.NewGitForge(d.Repo)
+	}
+	if has, known := forgeReleases(opts, forge); known && has != d.HasReleases {
+		if has {
+			e.add(SeverityWarning, "releases", "has_releases is false but the repository has %s releases", check.ForgeName(forge.Kind()))
+		} else {
+			e.add(SeverityWarning, "releases", "has_releases is true but the repository has no %s releases", check.ForgeName(forge.Kind()))
+		}
+	} else if !known && (len(tags) > 0) != d.HasReleases {
+		e.add(SeverityWarning, "releases", "has_releases is %t but the repository has %d tags", d.HasReleases, len(tags))
+	}
+
+	// archived
+	if archived, known := forgeArchived(opts, forge); known && archived {
+		e.Archived = true
+		e.add(SeverityWarning, "archived", "repository is archived")
+	}
+
+	// contents and last commit, from a shallow clone of the branch
+	tmp, err := os.MkdirTemp("", "hyprrelease-validate-")
+	if err != nil {
+		e.add(SeverityError, "clone", "%v", err)
+		return e
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "repo")
+	if err := shallowClone(opts, d.Repo, d.Branch, dir); err != nil {
+		e.add(SeverityError, "clone", "shallow clone of %s failed: %v", d.Branch, err)
+		return e
+	}
+
+	if d.Installer != "" {
+		if _, err := os.Stat(filepath.Join(dir, d.Installer)); err != nil {
+			e.add(SeverityError, "installer", "installer %s is missing", d.Installer)
+		}
+	} else if findInstaller(dir) == "" && !hasReadme(dir) {
+		e.add(SeverityWarning, "installer", "no installer script (%s) and no README; install falls back to copying files",
+			strings.Join(updateing.InstallerScripts, ", "))
+	}
+
+	if c, err := lastCommit(opts, dir); err == nil {
+		last := c.Time.UTC()
+		e.LastCommit = &last
+		if age := time.Since(last); age > opts.maxAge() {
+			e.add(SeverityWarning, "stale", "last commit on %s is %d days old", d.Branch, int(age.Hours()/24))
+		}
+	}
+	return e
+}
+
+func (e *EntryReport) add(s Severity, check, format string, args ...any) {
+	e.Issues = append(e.Issues, Issue{Severity: s, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// lsRemote : refs of a repository; "HEAD-symref" holds what HEAD points to
+func lsRemote(opts ValidateOptions, repo string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+	list, err := gitbackend.Default().LsRemote(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]string)
+	for _, r := range list {
+		if r.Name == "HEAD" && r.Target != "" {
+			refs["HEAD-symref"] = r.Target
+		}
+		if r.Hash != "" {
+			refs[r.Name] = r.Hash
+		}
+	}
+	return refs, nil
+}
+
+// shallowClone : depth-1 clone of branch into dir
+func shallowClone(opts ValidateOptions, repo, branch, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+	return gitbackend.Default().Clone(ctx, repo, dir, gitbackend.CloneOptions{Branch: branch, Depth: 1})
+}
+
+// lastCommit : the commit checked out in dir
+func lastCommit(opts ValidateOptions, dir string) (gitbackend.Commit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+	return gitbackend.Default().Commit(ctx, dir, "HEAD")
+}
+
+// forgeReleases : whether the repository has releases on its forge; known
+// is false for plain git hosts or when the forge API is unavailable
+func forgeReleases(opts ValidateOptions, forge check.Forge) (has, known bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+	releases, err := forge.Releases(ctx, 1)
+	if err != nil {
+		return false, false
+	}
+	return len(releases) > 0, true
+}
+
+// forgeArchived : archived flag of the repository on its forge
+func forgeArchived(opts ValidateOptions, forge check.Forge) (archived, known bool) {
+	if forge.Kind() == check.ForgeGit {
+		return false, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+	r, err := forge.Repo(ctx)
+	if err != nil {
+		return false, false
+	}
+	return r.Archived, true
+}
+
+func hasReadme(dir string) bool {
+	for _, name := range []string{"README.md", "README", "readme.md", "readme"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
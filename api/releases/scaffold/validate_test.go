@@ -0,0 +1,194 @@
+package scaffold
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+// bareRepo : bare repository in a temp dir whose default branch is branch,
+// holding files in one commit made at date, with the given tags on it
+func bareRepo(t *testing.T, branch, date string, files map[string]string, tags ...string) string {
+	t.Helper()
+	work, bare := t.TempDir(), filepath.Join(t.TempDir(), "repo.git")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	git(work, "init", "-q", "-b", branch)
+	for name, data := range files {
+		path := filepath.Join(work, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(data), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(work, "add", "-A")
+	git(work, "commit", "-q", "--allow-empty", "-m", "init")
+	for _, tag := range tags {
+		git(work, "tag", tag)
+	}
+	git(work, "clone", "-q", "--bare", work, bare)
+	return "file://" + bare
+}
+
+// issues : check names of the report's issues with their severity, e.g.
+// "error:branch"
+func issues(e EntryReport) []string {
+	var out []string
+	for _, is := range e.Issues {
+		out = append(out, string(is.Severity)+":"+is.Check)
+	}
+	return out
+}
+
+func TestValidateEntry(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	installer := map[string]string{"install.sh": "#!/bin/sh\n"}
+	renamed := bareRepo(t, "trunk", recent, installer)
+	tagged := bareRepo(t, "main", recent, installer, "v1.0", "v1.1")
+	untagged := bareRepo(t, "main", recent, installer)
+	readmeOnly := bareRepo(t, "main", recent, map[string]string{"README.md": "# dots\n"})
+	empty := bareRepo(t, "main", recent, map[string]string{"hypr/hyprland.conf": "\n"})
+	old := bareRepo(t, "main", "2020-01-01T00:00:00Z", installer)
+
+	tests := []struct {
+		name  string
+		entry summaryofversion.Dotfile
+		want  []string
+		// message : text the first issue must contain
+		message string
+	}{
+		{
+			name:    "renamed branch",
+			entry:   summaryofversion.Dotfile{Repo: renamed, Branch: "main"},
+			want:    []string{"error:branch"},
+			message: `(default branch is "trunk")`,
+		},
+		{
+			name:    "branch is a tag",
+			entry:   summaryofversion.Dotfile{Repo: tagged, Branch: "v1.0", HasReleases: true},
+			want:    []string{"warning:branch"},
+			message: `"v1.0" is a tag`,
+		},
+		{
+			name:  "tags and has_releases",
+			entry: summaryofversion.Dotfile{Repo: tagged, Branch: "main", HasReleases: true},
+		},
+		{
+			name:    "no tags",
+			entry:   summaryofversion.Dotfile{Repo: untagged, Branch: "main", HasReleases: true},
+			want:    []string{"warning:releases"},
+			message: "has_releases is true but the repository has 0 tags",
+		},
+		{
+			name:    "tags without has_releases",
+			entry:   summaryofversion.Dotfile{Repo: tagged, Branch: "main"},
+			want:    []string{"warning:releases"},
+			message: "has 2 tags",
+		},
+		{
+			name:    "missing installer",
+			entry:   summaryofversion.Dotfile{Repo: untagged, Branch: "main", Installer: "scripts/setup.sh"},
+			want:    []string{"error:installer"},
+			message: "installer scripts/setup.sh is missing",
+		},
+		{
+			name:  "README instead of installer",
+			entry: summaryofversion.Dotfile{Repo: readmeOnly, Branch: "main"},
+		},
+		{
+			name:  "neither installer nor README",
+			entry: summaryofversion.Dotfile{Repo: empty, Branch: "main"},
+			want:  []string{"warning:installer"},
+		},
+		{
+			name:    "stale",
+			entry:   summaryofversion.Dotfile{Repo: old, Branch: "main"},
+			want:    []string{"warning:stale"},
+			message: "last commit on main is",
+		},
+		{
+			name:    "unreachable",
+			entry:   summaryofversion.Dotfile{Repo: "file://" + filepath.Join(home, "missing.git"), Branch: "main"},
+			want:    []string{"error:reachable"},
+			message: "repository is not reachable",
+		},
+		{
+			name:  "schema",
+			entry: summaryofversion.Dotfile{Repo: "not a url", Branch: "main"},
+			want:  []string{"error:schema"},
+		},
+	}
+
+	for _, backend := range []gitbackend.Backend{gitbackend.Exec{}, gitbackend.Native{}} {
+		gitbackend.SetDefault(backend)
+		for _, tt := range tests {
+			t.Run(backend.Name()+"/"+tt.name, func(t *testing.T) {
+				d := tt.entry
+				d.Name = "test-dots"
+				e := ValidateEntry(d, ValidateOptions{MaxAge: 30 * 24 * time.Hour})
+				if got := issues(e); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+					t.Fatalf("issues = %v, want %v (%+v)", got, tt.want, e.Issues)
+				}
+				if tt.message != "" && !strings.Contains(e.Issues[0].Message, tt.message) {
+					t.Errorf("message = %q, want it to contain %q", e.Issues[0].Message, tt.message)
+				}
+				reached := len(tt.want) == 0 || tt.want[0] != "error:reachable" && tt.want[0] != "error:schema" && tt.want[0] != "error:branch"
+				if reached && e.LastCommit == nil {
+					t.Errorf("LastCommit not set")
+				}
+			})
+		}
+	}
+	gitbackend.SetDefault(nil)
+}
+
+func TestValidateRegistryCounts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	gitbackend.SetDefault(gitbackend.Exec{})
+	defer gitbackend.SetDefault(nil)
+
+	recent := time.Now().UTC().Format(time.RFC3339)
+	good := bareRepo(t, "main", recent, map[string]string{"install.sh": "#!/bin/sh\n"})
+	renamed := bareRepo(t, "trunk", recent, map[string]string{"install.sh": "#!/bin/sh\n"})
+	entries := []summaryofversion.Dotfile{
+		{Name: "good", Repo: good, Branch: "main"},
+		{Name: "renamed", Repo: renamed, Branch: "main"},
+		{Name: "untagged", Repo: good, Branch: "main", HasReleases: true},
+	}
+	r := ValidateRegistry(entries, ValidateOptions{Workers: 2})
+	if r.Errors != 1 || r.Warnings != 1 {
+		t.Errorf("errors %d, warnings %d; want 1 and 1", r.Errors, r.Warnings)
+	}
+	// reports keep the input order
+	for i, e := range r.Entries {
+		if e.Name != entries[i].Name {
+			t.Errorf("entry %d = %s, want %s", i, e.Name, entries[i].Name)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package summaryofversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// registryFile : on-disk registry file. TOML files hold [[dotfile]]
+// tables, JSON files a {"dotfiles": [...]} object:
+//
+//	[[dotfile]]
+//	name = "team-dots"                               # required, [A-Za-z0-9._+-]
+//	repo = "https://git.example.com/team/dots.git"   # required, URL or user@host:path
+//	author = "platform-team"
+//	branch = "main"                                  # default "main"
+//	has_releases = false
+//	description = "Team Hyprland setup."
+type registryFile struct {
+	Dotfiles []Dotfile `toml:"dotfile" json:"dotfiles"`
+}
+
+// DefaultBranch : branch used when a registry file entry sets none
+const DefaultBranch = "main"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+var scpLikeRepo = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/].*$`)
+
+// UserRegistryFile : registry file for the user's own entries
+func UserRegistryFile() string {
+	return filepath.Join(paths.ConfigDir(), "registry.d", "user.toml")
+}
+
+// LoadRegistryFile : reads and validates one registry file
+func LoadRegistryFile(path string) ([]Dotfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRegistryFile(path, data)
+}
+
+// parseRegistryFile : validates data as the registry file path; the
+// extension of path selects the format
+func parseRegistryFile(path string, data []byte) ([]Dotfile, error) {
+	var f registryFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		md, err := toml.Decode(string(data), &f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("%s: unknown field %q", path, undecoded[0].String())
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported registry file type", path)
+	}
+
+	for i := range f.Dotfiles {
+		if f.Dotfiles[i].Branch == "" {
+			f.Dotfiles[i].Branch = DefaultBranch
+		}
+		if err := Validate(f.Dotfiles[i]); err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %v", path, i+1, err)
+		}
+	}
+	return f.Dotfiles, nil
+}
+
+// Validate : checks an entry against the registry schema
+func Validate(d Dotfile) error {
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !validName.MatchString(d.Name) {
+		return fmt.Errorf("invalid name %q: use letters, digits, '.', '_', '+' or '-'", d.Name)
+	}
+	if d.Repo == "" {
+		return fmt.Errorf("%s: repo is required", d.Name)
+	}
+	if !validRepo(d.Repo) {
+		return fmt.Errorf("%s: invalid repo %q", d.Name, d.Repo)
+	}
+	if strings.ContainsAny(d.Branch, " \t\n") || strings.HasPrefix(d.Branch, "-") {
+		return fmt.Errorf("%s: invalid branch %q", d.Name, d.Branch)
+	}
+	return validateMeta(d)
+}
+
+func validRepo(repo string) bool {
+	if scpLikeRepo.MatchString(repo) {
+		return true
+	}
+	u, err := url.Parse(repo)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "https", "http", "git", "ssh":
+		return u.Host != "" && strings.Trim(u.Path, "/") != ""
+	case "file":
+		return u.Path != ""
+	}
+	return false
+}
+
+// LoadRegistry : entries of every source (see Sources), merged by name
+// (case-insensitive). The entry from the highest-priority source wins; on a
+// tie the source whose name sorts first wins. Each entry's Source field
+// names the source it came from. Broken sources and files are skipped and
+// reported in errs.
+func LoadRegistry() (entries []Dotfile, errs []error) {
+	sources, err := Sources()
+	if err != nil {
+		errs = append(errs, err)
+		sources = builtinSources()
+		sortSources(sources)
+	}
+
+	// sources are sorted highest priority first, so the first definition
+	// of a name is the winner
+	seen := make(map[string]bool)
+	for _, s := range sources {
+		loaded, loadErrs := s.load()
+		errs = append(errs, loadErrs...)
+		for _, d := range loaded {
+			key := strings.ToLower(d.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			d.Source = s.Name
+			entries = append(entries, d)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return builtinIndex(entries[i].Name) < builtinIndex(entries[j].Name)
+	})
+	return entries, errs
+}
+
+// builtinIndex : position in the built-in Registry; other names sort after
+// it, in source priority order
+func builtinIndex(name string) int {
+	for i, d := range Registry {
+		if strings.EqualFold(d.Name, name) {
+			return i
+		}
+	}
+	return len(Registry)
+}
+
+func mergeEntry(entries []Dotfile, d Dotfile) []Dotfile {
+	for i := range entries {
+		if strings.EqualFold(entries[i].Name, d.Name) {
+			entries[i] = d
+			return entries
+		}
+	}
+	return append(entries, d)
+}
+
+var (
+	registryMu     sync.Mutex
+	registryLoaded []Dotfile
+)
+
+// All : the merged registry, loaded on first use. Problems with registry
+// files are reported once and the broken files skipped.
+func All() []Dotfile {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if registryLoaded == nil {
+		entries, errs := LoadRegistry()
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, "⚠️ registry:", err)
+		}
+		registryLoaded = entries
+	}
+	return append([]Dotfile(nil), registryLoaded...)
+}
+
+// Reload : forgets the cached registry so the next All re-reads the files
+func Reload() {
+	registryMu.Lock()
+	registryLoaded = nil
+	registryMu.Unlock()
+}
@@ -0,0 +1,119 @@
+package summaryofversion
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Entries describe their lifecycle with three optional fields:
+//
+//	deprecated  = true                                  # no longer maintained
+//	replaced_by = "HyDE"                                # successor registry entry
+//	moved_to    = "https://github.com/new-owner/dots"   # same project, new repository
+//
+// moved_to is applied wherever the entry is installed from; replaced_by is
+// a suggestion that install and update offer to follow.
+
+// maxRedirects : longest replaced_by chain Resolve follows
+const maxRedirects = 8
+
+// ErrNoSuccessor : replaced_by names an entry that is not in the registry
+var ErrNoSuccessor = errors.New("not in the registry")
+
+// Redirect kinds
+const (
+	RedirectReplaced = "replaced_by"
+	RedirectMoved    = "moved_to"
+)
+
+// Redirect : one step taken while resolving an entry
+type Redirect struct {
+	Kind string `json:"kind"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (r Redirect) String() string {
+	if r.Kind == RedirectMoved {
+		return fmt.Sprintf("repository moved: %s → %s", r.From, r.To)
+	}
+	return fmt.Sprintf("%s is replaced by %s", r.From, r.To)
+}
+
+// Notice : one-line lifecycle warning for the entry, empty if it has none
+func (d Dotfile) Notice() string {
+	var parts []string
+	if d.Deprecated {
+		parts = append(parts, "deprecated")
+	}
+	if d.ReplacedBy != "" {
+		parts = append(parts, "replaced by "+d.ReplacedBy)
+	}
+	if d.MovedTo != "" {
+		parts = append(parts, "moved to "+d.MovedTo)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Moved : the entry with its repository redirected to moved_to, if set
+func (d Dotfile) Moved() (Dotfile, *Redirect) {
+	if d.MovedTo == "" || d.MovedTo == d.Repo {
+		return d, nil
+	}
+	r := &Redirect{Kind: RedirectMoved, From: d.Repo, To: d.MovedTo}
+	d.Repo = d.MovedTo
+	return d, r
+}
+
+// Successor : the registry entry named by replaced_by
+func Successor(d Dotfile) (*Dotfile, error) {
+	if d.ReplacedBy == "" {
+		return nil, nil
+	}
+	next := GetDotfileByName(d.ReplacedBy)
+	if next == nil {
+		return nil, fmt.Errorf("%s is replaced by %s, which is %w", d.Name, d.ReplacedBy, ErrNoSuccessor)
+	}
+	return next, nil
+}
+
+// Resolve : looks name up and follows its redirects. moved_to is always
+// applied; each replaced_by step is taken when follow (nil: always) agrees.
+// Returns the entry resolution stopped at and the steps taken, in order.
+// A replaced_by loop, a chain longer than maxRedirects and a successor
+// missing from the registry (ErrNoSuccessor) are errors; the entry reached
+// so far is returned with them.
+func Resolve(name string, follow func(from, to Dotfile) (bool, error)) (*Dotfile, []Redirect, error) {
+	d := GetDotfileByName(name)
+	if d == nil {
+		return nil, nil, NotFoundError(name)
+	}
+	var redirects []Redirect
+	seen := map[string]bool{strings.ToLower(d.Name): true}
+	for {
+		moved, r := d.Moved()
+		if r != nil {
+			redirects = append(redirects, *r)
+		}
+		next, err := Successor(moved)
+		if err != nil || next == nil {
+			return &moved, redirects, err
+		}
+		if seen[strings.ToLower(next.Name)] {
+			return &moved, redirects, fmt.Errorf("replaced_by loop at %s → %s", moved.Name, next.Name)
+		}
+		if len(seen) > maxRedirects {
+			return &moved, redirects, fmt.Errorf("%s: more than %d replaced_by redirects", name, maxRedirects)
+		}
+		if follow != nil {
+			ok, err := follow(moved, *next)
+			if err != nil || !ok {
+				return &moved, redirects, err
+			}
+		}
+		seen[strings.ToLower(next.Name)] = true
+		redirects = append(redirects, Redirect{Kind: RedirectReplaced, From: moved.Name, To: next.Name})
+		d = next
+	}
+}
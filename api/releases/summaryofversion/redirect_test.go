@@ -0,0 +1,79 @@
+package summaryofversion
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// redirectEntry : a registry entry in TOML with extra fields appended
+func redirectEntry(name, extra string) string {
+	return entry(name, "redirect test") + extra
+}
+
+func TestResolve(t *testing.T) {
+	isolate(t)
+	var index strings.Builder
+	index.WriteString(redirectEntry("Old", "replaced_by = \"Middle\"\n"))
+	index.WriteString(redirectEntry("Middle", "replaced_by = \"New\"\nmoved_to = \"https://git.example.com/middle2.git\"\n"))
+	index.WriteString(redirectEntry("New", ""))
+	index.WriteString(redirectEntry("Ping", "replaced_by = \"Pong\"\n"))
+	index.WriteString(redirectEntry("Pong", "replaced_by = \"ping\"\n"))
+	index.WriteString(redirectEntry("Orphan", "replaced_by = \"Gone\"\n"))
+	// Chain0 → Chain1 → … → Chain9, one hop more than Resolve follows
+	for i := 0; i < maxRedirects+1; i++ {
+		index.WriteString(redirectEntry(fmt.Sprintf("Chain%d", i), fmt.Sprintf("replaced_by = \"Chain%d\"\n", i+1)))
+	}
+	index.WriteString(redirectEntry(fmt.Sprintf("Chain%d", maxRedirects+1), ""))
+	writeFile(t, filepath.Join(paths.ConfigDir(), "registry.d", "user.toml"), index.String())
+
+	d, redirects, err := Resolve("old", nil)
+	if err != nil || d.Name != "New" {
+		t.Fatalf("Resolve(old) = %+v, %v; want New", d, err)
+	}
+	var steps []string
+	for _, r := range redirects {
+		steps = append(steps, r.Kind+":"+r.From+">"+r.To)
+	}
+	want := "replaced_by:Old>Middle moved_to:https://git.example.com/middle.git>https://git.example.com/middle2.git replaced_by:Middle>New"
+	if got := strings.Join(steps, " "); got != want {
+		t.Errorf("redirects = %s, want %s", got, want)
+	}
+
+	// follow is asked before each replaced_by step and can stop the chain
+	var asked []string
+	d, _, err = Resolve("Old", func(from, to Dotfile) (bool, error) {
+		asked = append(asked, from.Name+">"+to.Name)
+		return to.Name != "New", nil
+	})
+	if err != nil || d.Name != "Middle" || d.Repo != "https://git.example.com/middle2.git" {
+		t.Errorf("declined Resolve = %+v, %v; want Middle at its new repository", d, err)
+	}
+	if got := strings.Join(asked, " "); got != "Old>Middle Middle>New" {
+		t.Errorf("follow asked %s", got)
+	}
+	stop := errors.New("no answer")
+	if _, _, err := Resolve("Old", func(from, to Dotfile) (bool, error) { return false, stop }); !errors.Is(err, stop) {
+		t.Errorf("Resolve with failing follow = %v, want %v", err, stop)
+	}
+
+	if d, _, err := Resolve("Ping", nil); err == nil || !strings.Contains(err.Error(), "loop") || d == nil {
+		t.Errorf("Resolve(Ping) = %+v, %v; want a loop error", d, err)
+	}
+	if d, _, err := Resolve("Chain0", nil); err == nil || !strings.Contains(err.Error(), "more than") || d == nil {
+		t.Errorf("Resolve(Chain0) = %+v, %v; want a chain length error", d, err)
+	}
+	if d, _, err := Resolve("Chain1", nil); err != nil || d.Name != fmt.Sprintf("Chain%d", maxRedirects+1) {
+		t.Errorf("Resolve(Chain1) = %+v, %v; want the end of the chain", d, err)
+	}
+	if d, _, err := Resolve("Orphan", nil); !errors.Is(err, ErrNoSuccessor) || d == nil || d.Name != "Orphan" {
+		t.Errorf("Resolve(Orphan) = %+v, %v; want ErrNoSuccessor", d, err)
+	}
+	if _, _, err := Resolve("Missing", nil); err == nil {
+		t.Errorf("Resolve(Missing): no error")
+	}
+}
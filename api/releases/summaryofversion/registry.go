@@ -1,14 +1,43 @@
 package summaryofversion
 
+// Dotfile : a registry entry. Field tags define the registry file schema
+// (see LoadRegistryFile).
 type Dotfile struct {
-	Name         string
-	Author       string
-	Repo         string
-	Branch       string
-	HasReleases  bool
-	Description  string
+	Name        string `toml:"name" json:"name"`
+	Author      string `toml:"author,omitempty" json:"author"`
+	Repo        string `toml:"repo" json:"repo"`
+	Branch      string `toml:"branch" json:"branch"`
+	HasReleases bool   `toml:"has_releases" json:"has_releases"`
+	Description string `toml:"description,omitempty" json:"description"`
+
+	// Optional metadata (see schema.go)
+	Tags        []string `toml:"tags,omitempty" json:"tags,omitempty"`
+	Distros     []string `toml:"distros,omitempty" json:"distros,omitempty"`
+	Packages    []string `toml:"packages,omitempty" json:"packages,omitempty"`
+	MinHyprland string   `toml:"min_hyprland,omitempty" json:"min_hyprland,omitempty"`
+	MaxHyprland string   `toml:"max_hyprland,omitempty" json:"max_hyprland,omitempty"`
+	License     string   `toml:"license,omitempty" json:"license,omitempty"`
+	Screenshots []string `toml:"screenshots,omitempty" json:"screenshots,omitempty"`
+	Homepage    string   `toml:"homepage,omitempty" json:"homepage,omitempty"`
+	Installer   string   `toml:"installer,omitempty" json:"installer,omitempty"`
+	Forge       string   `toml:"forge,omitempty" json:"forge,omitempty"`
+
+	// Lifecycle (see redirect.go)
+	Deprecated bool   `toml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	ReplacedBy string `toml:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+	MovedTo    string `toml:"moved_to,omitempty" json:"moved_to,omitempty"`
+
+	// Source : registry source the entry was loaded from (set by LoadRegistry)
+	Source string `toml:"-" json:"source,omitempty"`
+	// SignedBy : id of the trusted key that signed the index the entry was
+	// loaded from; empty for local sources and accepted unsigned indexes
+	SignedBy string `toml:"-" json:"signed_by,omitempty"`
 }
 
+// Registry : built-in entries, compiled into the binary. Entries from
+// registry files are merged on top of these; use All to get the result.
+// Metadata is limited to what each project states upstream (e.g. the
+// distributions its installer supports); tags are left to registry files.
 var Registry = []Dotfile{
 	{
 		Name:        "HyDE",
@@ -17,6 +46,7 @@ var Registry = []Dotfile{
 		Branch:      "master",
 		HasReleases: false,
 		Description: "Dynamic modular Hyprland setup.",
+		Distros:     []string{"arch"},
 	},
 	{
 		Name:        "Hyprdots",
@@ -25,6 +55,9 @@ var Registry = []Dotfile{
 		Branch:      "main",
 		HasReleases: false,
 		Description: "Full-featured Arch-based Hyprland dotfiles.",
+		Distros:     []string{"arch"},
+		Deprecated:  true,
+		ReplacedBy:  "HyDE",
 	},
 	{
 		Name:        "JaKooLit-Dots",
@@ -33,6 +66,7 @@ var Registry = []Dotfile{
 		Branch:      "main",
 		HasReleases: false,
 		Description: "Multi-distro prebuilt Hyprland configurations.",
+		Distros:     []string{"arch", "fedora", "debian", "ubuntu", "opensuse", "nixos"},
 	},
 	{
 		Name:        "end4-dots",
@@ -41,6 +75,7 @@ var Registry = []Dotfile{
 		Branch:      "main",
 		HasReleases: false,
 		Description: "User-centric, aesthetic rice.",
+		Distros:     []string{"arch"},
 	},
 	{
 		Name:        "ML4W-Dotfiles",
@@ -49,6 +84,7 @@ var Registry = []Dotfile{
 		Branch:      "main",
 		HasReleases: true,
 		Description: "Production-ready Hyprland workspace.",
+		Distros:     []string{"arch", "fedora", "opensuse"},
 	},
 	{
 		Name:        "taylor-hyprland",
@@ -57,6 +93,7 @@ var Registry = []Dotfile{
 		Branch:      "main",
 		HasReleases: false,
 		Description: "Minimal Hyprland config for Arch users.",
+		Distros:     []string{"arch"},
 	},
 	{
 		Name:        "elifouts-dotfiles",
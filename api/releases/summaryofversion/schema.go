@@ -0,0 +1,183 @@
+package summaryofversion
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+// Registry entries may carry optional metadata:
+//
+//	tags         = ["minimal", "animated", "nvidia-friendly"]
+//	distros      = ["arch", "fedora"]        # os-release IDs
+//	packages     = ["waybar", "rofi-wayland"]
+//	min_hyprland = "0.41"
+//	max_hyprland = "0.45.2"
+//	license      = "GPL-3.0-or-later"
+//	screenshots  = ["https://..."]
+//	homepage     = "https://..."
+//	installer    = "scripts/setup.sh"        # tried before hyprrelease.sh / install.sh
+//	forge        = "gitea"                   # see Forges; default: detected from repo
+//
+// An entry without distros makes no claim about distribution support.
+
+var (
+	validTag     = regexp.MustCompile(`^[a-z0-9][a-z0-9+-]*$`)
+	validDistro  = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	validPackage = regexp.MustCompile(`^[A-Za-z0-9@_+][A-Za-z0-9@._+:/-]*$`)
+	validVersion = regexp.MustCompile(`^v?\d+(\.\d+){0,3}$`)
+)
+
+// Forges : accepted values of the forge field. forgejo and codeberg are
+// served by the Gitea API, sourcehut by plain git.
+var Forges = []string{"github", "gitlab", "gitea", "forgejo", "codeberg", "sourcehut", "git"}
+
+// osRelease : os-release file read by HostDistros
+var osRelease = "/etc/os-release"
+
+// validateMeta : checks the optional metadata fields of d
+func validateMeta(d Dotfile) error {
+	for _, t := range d.Tags {
+		if !validTag.MatchString(t) {
+			return fmt.Errorf("%s: invalid tag %q: use lowercase letters, digits, '+' or '-'", d.Name, t)
+		}
+	}
+	for _, id := range d.Distros {
+		if !validDistro.MatchString(id) {
+			return fmt.Errorf("%s: invalid distro %q: use the lowercase os-release ID", d.Name, id)
+		}
+	}
+	for _, p := range d.Packages {
+		if !validPackage.MatchString(p) {
+			return fmt.Errorf("%s: invalid package %q", d.Name, p)
+		}
+	}
+	for _, v := range []string{d.MinHyprland, d.MaxHyprland} {
+		if v != "" && !validVersion.MatchString(v) {
+			return fmt.Errorf("%s: invalid Hyprland version %q: expected e.g. 0.45 or 0.45.2", d.Name, v)
+		}
+	}
+	lo, _ := version.Parse(d.MinHyprland)
+	hi, _ := version.Parse(d.MaxHyprland)
+	if !lo.IsZero() && !hi.IsZero() && lo.Compare(hi) > 0 {
+		return fmt.Errorf("%s: min_hyprland %s is newer than max_hyprland %s", d.Name, d.MinHyprland, d.MaxHyprland)
+	}
+	if strings.ContainsAny(d.License, "\n\t") {
+		return fmt.Errorf("%s: invalid license %q", d.Name, d.License)
+	}
+	for _, s := range d.Screenshots {
+		if !validWebURL(s) {
+			return fmt.Errorf("%s: invalid screenshot URL %q", d.Name, s)
+		}
+	}
+	if d.Homepage != "" && !validWebURL(d.Homepage) {
+		return fmt.Errorf("%s: invalid homepage %q", d.Name, d.Homepage)
+	}
+	if d.Installer != "" && (filepath.IsAbs(d.Installer) || !filepath.IsLocal(d.Installer)) {
+		return fmt.Errorf("%s: installer %q must be a path inside the repository", d.Name, d.Installer)
+	}
+	if d.Forge != "" && !slices.Contains(Forges, d.Forge) {
+		return fmt.Errorf("%s: unknown forge %q: use one of %s", d.Name, d.Forge, strings.Join(Forges, ", "))
+	}
+	if d.ReplacedBy != "" && (!validName.MatchString(d.ReplacedBy) || strings.EqualFold(d.ReplacedBy, d.Name)) {
+		return fmt.Errorf("%s: invalid replaced_by %q", d.Name, d.ReplacedBy)
+	}
+	if d.MovedTo != "" && !validRepo(d.MovedTo) {
+		return fmt.Errorf("%s: invalid moved_to %q", d.Name, d.MovedTo)
+	}
+	return nil
+}
+
+func validWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// HasTag : reports whether the entry is tagged with tag (case-insensitive)
+func (d Dotfile) HasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsDistro : reports whether one of the os-release IDs is listed in
+// the entry's distros
+func (d Dotfile) SupportsDistro(ids ...string) bool {
+	for _, have := range d.Distros {
+		for _, id := range ids {
+			if strings.EqualFold(have, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SupportsHyprland : reports whether v lies within the entry's min/max
+// range; unknown versions and entries without a range always match
+func (d Dotfile) SupportsHyprland(v version.Version) bool {
+	if v.IsZero() {
+		return true
+	}
+	if lo, err := version.Parse(d.MinHyprland); err == nil && v.Compare(lo) < 0 {
+		return false
+	}
+	if hi, err := version.Parse(d.MaxHyprland); err == nil && v.Compare(hi) > 0 {
+		return false
+	}
+	return true
+}
+
+// HyprlandRange : human-readable version range, empty if none is set
+func (d Dotfile) HyprlandRange() string {
+	switch {
+	case d.MinHyprland != "" && d.MaxHyprland != "":
+		return d.MinHyprland + " – " + d.MaxHyprland
+	case d.MinHyprland != "":
+		return ">= " + d.MinHyprland
+	case d.MaxHyprland != "":
+		return "<= " + d.MaxHyprland
+	}
+	return ""
+}
+
+// HostDistros : os-release ID of this machine followed by its ID_LIKE
+// entries, e.g. ["endeavouros", "arch"]
+func HostDistros() []string {
+	f, err := os.Open(osRelease)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var id string
+	var like []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok {
+			continue
+		}
+		value = strings.ToLower(strings.Trim(value, `"'`))
+		switch key {
+		case "ID":
+			id = value
+		case "ID_LIKE":
+			like = strings.Fields(value)
+		}
+	}
+	if id == "" {
+		return like
+	}
+	return append([]string{id}, like...)
+}
@@ -0,0 +1,47 @@
+package summaryofversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
+)
+
+func TestSupportsHyprland(t *testing.T) {
+	d := Dotfile{Name: "ranged", MinHyprland: "0.41", MaxHyprland: "v0.45.2"}
+	for banner, want := range map[string]bool{
+		"":                                  true, // unknown version
+		"Hyprland 0.40.0 built from branch": false,
+		"Hyprland 0.41.0 built from branch": true,
+		"Hyprland, built from branch main at commit 918d834 (version: bump to 0.45.2).\nTag: v0.45.2, commits: 5436": true,
+		"Tag: v0.45.2-9-g918d834, commits: 5445": false, // past the tag
+		"Hyprland 0.46.0-rc1 built from branch":  false,
+		"Hyprland 0.9.0 built from branch":       false, // 9 < 41, not "0.9" > "0.41"
+	} {
+		v, _ := version.ParseBanner(banner)
+		if got := d.SupportsHyprland(v); got != want {
+			t.Errorf("SupportsHyprland(%s) = %t, want %t", v, got, want)
+		}
+	}
+	if !(Dotfile{Name: "any"}).SupportsHyprland(version.Version{Release: []int{0, 30}}) {
+		t.Errorf("entry without a range rejected 0.30")
+	}
+}
+
+func TestValidateHyprlandRange(t *testing.T) {
+	for _, tt := range []struct {
+		min, max string
+		err      string
+	}{
+		{"0.41", "0.45.2", ""},
+		{"0.45", "0.45.0", ""},
+		{"0.45.2", "0.45", "is newer than"},
+		{"0.10", "0.9", "is newer than"},
+		{"0.45-rc1", "", "invalid Hyprland version"},
+	} {
+		err := validateMeta(Dotfile{Name: "ranged", MinHyprland: tt.min, MaxHyprland: tt.max})
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("validateMeta(%s, %s) = %v, want %q", tt.min, tt.max, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package summaryofversion
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Filter : restricts search results; zero fields match everything
+type Filter struct {
+	Author string   // case-insensitive author name
+	Tags   []string // every tag must be present
+	Distro string   // os-release ID; "auto" uses HostDistros
+	// HasReleases : nil matches both
+	HasReleases *bool
+}
+
+// Matches : reports whether d passes the filter
+func (f Filter) Matches(d Dotfile) bool {
+	if f.Author != "" && !strings.EqualFold(f.Author, d.Author) {
+		return false
+	}
+	for _, t := range f.Tags {
+		if t = strings.TrimSpace(t); t != "" && !d.HasTag(t) {
+			return false
+		}
+	}
+	if f.Distro != "" {
+		ids := []string{f.Distro}
+		if f.Distro == "auto" {
+			ids = HostDistros()
+		}
+		if !d.SupportsDistro(ids...) {
+			return false
+		}
+	}
+	if f.HasReleases != nil && *f.HasReleases != d.HasReleases {
+		return false
+	}
+	return true
+}
+
+// SearchResult : a matching entry and how well it matched
+type SearchResult struct {
+	Dotfile Dotfile `json:"dotfile"`
+	// Score : 0–100, higher is better
+	Score int `json:"score"`
+	// Match : what the query matched: name, fuzzy, text or filter
+	Match string `json:"match"`
+}
+
+// Search : registry entries matching query and filter, best match first.
+// The query is matched fuzzily against names (case, punctuation and small
+// typos are ignored) and as full text against author, description and
+// tags. An empty query returns every entry passing the filter.
+func Search(query string, f Filter) []SearchResult {
+	return SearchEntries(All(), query, f)
+}
+
+// SearchEntries : Search over the given entries
+func SearchEntries(entries []Dotfile, query string, f Filter) []SearchResult {
+	results := []SearchResult{}
+	for _, d := range entries {
+		if !f.Matches(d) {
+			continue
+		}
+		if strings.TrimSpace(query) == "" {
+			results = append(results, SearchResult{Dotfile: d, Score: 100, Match: "filter"})
+			continue
+		}
+		score, match := nameScore(query, d.Name)
+		if s := textScore(query, d); s > score {
+			score, match = s, "text"
+		}
+		if score > 0 {
+			results = append(results, SearchResult{Dotfile: d, Score: score, Match: match})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// nameScore : fuzzy name match. Exact, prefix and substring matches rank
+// above subsequence matches ("hyde" ~ "HyDE", "jkdots" ~ "JaKooLit-Dots"),
+// which rank above names within a small edit distance ("hyprdost").
+func nameScore(query, name string) (int, string) {
+	q, n := normalize(query), normalize(name)
+	switch {
+	case q == "":
+		return 0, ""
+	case q == n:
+		return 100, "name"
+	case strings.HasPrefix(n, q):
+		return 90, "name"
+	case strings.Contains(n, q):
+		return 80, "name"
+	}
+	if gaps, ok := subsequence(q, n); ok && len(q) >= 2 {
+		return max(70-2*gaps, 45), "fuzzy"
+	}
+	// typos: compare against the name and against its prefix of equal length
+	limit := max(1, len(q)/4)
+	dist := levenshtein(q, n)
+	if len(n) > len(q) {
+		dist = min(dist, levenshtein(q, n[:len(q)]))
+	}
+	if dist <= limit {
+		return 60 - 10*dist, "fuzzy"
+	}
+	return 0, ""
+}
+
+// textScore : every query word must occur in author, description or tags
+func textScore(query string, d Dotfile) int {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return 0
+	}
+	text := strings.ToLower(strings.Join(append([]string{d.Author, d.Description}, d.Tags...), " "))
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return 0
+		}
+	}
+	return min(40+5*len(words), 55)
+}
+
+// normalize : lowercase letters and digits only
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// subsequence : reports whether q's characters appear in order in s and
+// counts the gaps between them
+func subsequence(q, s string) (gaps int, ok bool) {
+	i, last := 0, -1
+	for j := 0; j < len(s) && i < len(q); j++ {
+		if s[j] == q[i] {
+			if last >= 0 && j != last+1 {
+				gaps++
+			}
+			last = j
+			i++
+		}
+	}
+	return gaps, i == len(q)
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,235 @@
+package summaryofversion
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// Registry index signatures are detached ed25519 signatures over the exact
+// bytes of an index file, stored base64-encoded next to it as <index>.sig.
+// Trusted public keys are base64-encoded files named <key-id>.pub in the
+// TrustedKeyDirs; '#' lines in key files are comments.
+const SigSuffix = ".sig"
+
+// AllowUnsignedEnv : set to 1 to accept unsigned or unverifiable indexes
+// from every source
+const AllowUnsignedEnv = "HYPR_RELEASE_ALLOW_UNSIGNED"
+
+var (
+	// ErrUnsigned : the index has no signature
+	ErrUnsigned = errors.New("index is not signed")
+	// ErrBadSignature : no trusted key verifies the signature
+	ErrBadSignature = errors.New("signature does not match any trusted key")
+)
+
+// TrustedKey : a public key allowed to sign registry indexes
+type TrustedKey struct {
+	ID   string
+	Key  ed25519.PublicKey
+	Path string
+}
+
+// Fingerprint : short hex digest identifying the key
+func (k TrustedKey) Fingerprint() string {
+	sum := sha256.Sum256(k.Key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// TrustedKeyDirs : directories holding trusted *.pub keys
+func TrustedKeyDirs() []string {
+	return []string{
+		"/etc/hypr-release/trusted-keys.d",
+		filepath.Join(paths.ConfigDir(), "trusted-keys.d"),
+	}
+}
+
+// TrustedKeys : every trusted key, sorted by id. A user key replaces a
+// system key with the same id.
+func TrustedKeys() ([]TrustedKey, error) {
+	byID := make(map[string]TrustedKey)
+	for _, dir := range TrustedKeyDirs() {
+		files, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() || filepath.Ext(f.Name()) != ".pub" {
+				continue
+			}
+			path := filepath.Join(dir, f.Name())
+			key, err := readKeyFile(path, ed25519.PublicKeySize)
+			if err != nil {
+				return nil, err
+			}
+			id := strings.TrimSuffix(f.Name(), ".pub")
+			byID[id] = TrustedKey{ID: id, Key: ed25519.PublicKey(key), Path: path}
+		}
+	}
+
+	keys := make([]TrustedKey, 0, len(byID))
+	for _, k := range byID {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// VerifyIndex : checks sig (base64) over data against keys and returns the
+// key that made it
+func VerifyIndex(data, sig []byte, keys []TrustedKey) (TrustedKey, error) {
+	if len(strings.TrimSpace(string(sig))) == 0 {
+		return TrustedKey{}, ErrUnsigned
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return TrustedKey{}, fmt.Errorf("malformed signature")
+	}
+	for _, k := range keys {
+		if ed25519.Verify(k.Key, data, raw) {
+			return k, nil
+		}
+	}
+	return TrustedKey{}, ErrBadSignature
+}
+
+// VerifyIndexFile : VerifyIndex for path and path+SigSuffix
+func VerifyIndexFile(path string, keys []TrustedKey) (TrustedKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TrustedKey{}, err
+	}
+	sig, err := readSignature(path)
+	if err != nil {
+		return TrustedKey{}, err
+	}
+	return VerifyIndex(data, sig, keys)
+}
+
+// readSignature : contents of path+SigSuffix; nil if there is none
+func readSignature(path string) ([]byte, error) {
+	sig, err := os.ReadFile(path + SigSuffix)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return sig, err
+}
+
+// SignIndex : base64 detached signature of data, as stored in .sig files
+func SignIndex(priv ed25519.PrivateKey, data []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)) + "\n")
+}
+
+// GenerateKey : new signing key pair, base64-encoded as key files expect
+func GenerateKey() (pub, priv []byte, err error) {
+	p, k, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(p) + "\n"), []byte(base64.StdEncoding.EncodeToString(k) + "\n"), nil
+}
+
+// LoadPrivateKey : reads a base64 signing key written by GenerateKey
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	key, err := readKeyFile(path, ed25519.PrivateKeySize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+func readKeyFile(path string, size int) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b64 strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			b64.WriteString(line)
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(b64.String())
+	if err != nil || len(key) != size {
+		return nil, fmt.Errorf("%s: not a base64 ed25519 key", path)
+	}
+	return key, nil
+}
+
+// verifySourceFile : verifies data, the contents of the index file path of
+// source s, against path+SigSuffix. Unsigned or unverifiable files are an
+// error unless the source allows them; in that case the returned signer is
+// empty.
+func verifySourceFile(s Source, path string, data []byte, keys []TrustedKey) (string, error) {
+	sig, err := readSignature(path)
+	if err == nil {
+		var k TrustedKey
+		if k, err = VerifyIndex(data, sig, s.allowedKeys(keys)); err == nil {
+			return k.ID, nil
+		}
+	}
+	if s.unsignedAllowed() {
+		return "", nil
+	}
+	return "", fmt.Errorf("source %s: %s: %v (set allow_unsigned = true for the source or %s=1 to accept)",
+		s.Name, filepath.Base(path), err, AllowUnsignedEnv)
+}
+
+// verifySource : checks every index file of a configured source and
+// returns the ids of the keys that signed them
+func verifySource(s Source) (signers []string, err error) {
+	keys, err := TrustedKeys()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, f := range s.indexFiles() {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		id, err := verifySourceFile(s, f, data, keys)
+		if err != nil {
+			return nil, err
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			signers = append(signers, id)
+		}
+	}
+	return signers, nil
+}
+
+// allowedKeys : trusted keys this source may be signed with
+func (s Source) allowedKeys(keys []TrustedKey) []TrustedKey {
+	if len(s.Keys) == 0 {
+		return keys
+	}
+	var allowed []TrustedKey
+	for _, k := range keys {
+		for _, id := range s.Keys {
+			if k.ID == id {
+				allowed = append(allowed, k)
+			}
+		}
+	}
+	return allowed
+}
+
+func (s Source) unsignedAllowed() bool {
+	return s.AllowUnsigned || os.Getenv(AllowUnsignedEnv) == "1"
+}
@@ -0,0 +1,479 @@
+package summaryofversion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hyprcommunity/hypr-release/api/releases/gitbackend"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// Source types
+const (
+	SourceBuiltin = "builtin"
+	SourceDir     = "dir"
+	SourceGit     = "git"
+	SourceHTTP    = "http"
+)
+
+// DefaultSourcePriority : priority of a configured source that sets none
+const DefaultSourcePriority = 50
+
+// Source : a place registry entries come from. When two sources define the
+// same dotfile name, the higher Priority wins; on a tie the source whose
+// name sorts first wins.
+//
+// Besides the built-in entries (priority 0) and the registry.d directories
+// (vendor 10, system 20, user 90), sources are configured in sources.toml
+// under /etc/hypr-release and $XDG_CONFIG_HOME/hypr-release:
+//
+//	[[source]]
+//	name = "team"
+//	type = "git"                                 # dir, git or http
+//	url = "https://git.example.com/team/registry.git"
+//	branch = "main"                              # git only
+//	index = "index.toml"                         # git only; default index.toml, then index.json
+//	priority = 60
+//	keys = ["team"]                              # optional: only these trusted keys may sign
+//	allow_unsigned = false
+//
+// Index files of configured sources must carry a detached signature from a
+// trusted key (see signature.go). The built-in entries and the registry.d
+// directories are local files and are not verified.
+type Source struct {
+	Name          string   `toml:"name" json:"name"`
+	Type          string   `toml:"type" json:"type"`
+	URL           string   `toml:"url" json:"url,omitempty"`
+	Branch        string   `toml:"branch" json:"branch,omitempty"`
+	Index         string   `toml:"index" json:"index,omitempty"`
+	Priority      int      `toml:"priority" json:"priority"`
+	Keys          []string `toml:"keys" json:"keys,omitempty"`
+	AllowUnsigned bool     `toml:"allow_unsigned" json:"allow_unsigned,omitempty"`
+
+	// local : built-in source that needs no signature
+	local bool
+}
+
+type sourcesFile struct {
+	Sources []struct {
+		Name          string   `toml:"name"`
+		Type          string   `toml:"type"`
+		URL           string   `toml:"url"`
+		Branch        string   `toml:"branch"`
+		Index         string   `toml:"index"`
+		Priority      *int     `toml:"priority"`
+		Keys          []string `toml:"keys"`
+		AllowUnsigned bool     `toml:"allow_unsigned"`
+	} `toml:"source"`
+}
+
+// SourceConfigFiles : sources.toml files, lowest precedence first
+func SourceConfigFiles() []string {
+	return []string{
+		"/etc/hypr-release/sources.toml",
+		filepath.Join(paths.ConfigDir(), "sources.toml"),
+	}
+}
+
+// builtinSources : sources that exist without any configuration
+func builtinSources() []Source {
+	return []Source{
+		{Name: "builtin", Type: SourceBuiltin, Priority: 0, local: true},
+		{Name: "vendor", Type: SourceDir, URL: "/usr/share/hypr-release/registry.d", Priority: 10, local: true},
+		{Name: "system", Type: SourceDir, URL: "/etc/hypr-release/registry.d", Priority: 20, local: true},
+		{Name: "user", Type: SourceDir, URL: filepath.Join(paths.ConfigDir(), "registry.d"), Priority: 90, local: true},
+	}
+}
+
+// RegistryDirs : registry.d directories scanned for *.toml / *.json files
+func RegistryDirs() []string {
+	var dirs []string
+	for _, s := range builtinSources() {
+		if s.Type == SourceDir {
+			dirs = append(dirs, s.URL)
+		}
+	}
+	return dirs
+}
+
+// Sources : built-in and configured sources, highest priority first. A
+// source in a later config file replaces one with the same name.
+func Sources() ([]Source, error) {
+	sources := builtinSources()
+	for _, file := range SourceConfigFiles() {
+		var f sourcesFile
+		md, err := toml.DecodeFile(file, &f)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("%s: unknown field %q", file, undecoded[0].String())
+		}
+		for _, entry := range f.Sources {
+			s := Source{
+				Name:          entry.Name,
+				Type:          entry.Type,
+				URL:           entry.URL,
+				Branch:        entry.Branch,
+				Index:         entry.Index,
+				Keys:          entry.Keys,
+				AllowUnsigned: entry.AllowUnsigned,
+			}
+			s.Priority = DefaultSourcePriority
+			if entry.Priority != nil {
+				s.Priority = *entry.Priority
+			}
+			if err := validateSource(s); err != nil {
+				return nil, fmt.Errorf("%s: %v", file, err)
+			}
+			sources = replaceSource(sources, s)
+		}
+	}
+	sortSources(sources)
+	return sources, nil
+}
+
+func validateSource(s Source) error {
+	if !validName.MatchString(s.Name) {
+		return fmt.Errorf("invalid source name %q", s.Name)
+	}
+	switch s.Type {
+	case SourceDir, SourceGit, SourceHTTP:
+	default:
+		return fmt.Errorf("source %s: unknown type %q (dir, git or http)", s.Name, s.Type)
+	}
+	if s.URL == "" {
+		return fmt.Errorf("source %s: url is required", s.Name)
+	}
+	if s.Type == SourceHTTP && !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return fmt.Errorf("source %s: http source needs an http(s) url", s.Name)
+	}
+	return nil
+}
+
+func replaceSource(sources []Source, s Source) []Source {
+	for i := range sources {
+		if sources[i].Name == s.Name {
+			sources[i] = s
+			return sources
+		}
+	}
+	return append(sources, s)
+}
+
+// sortSources : highest priority first, then by name
+func sortSources(sources []Source) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		if sources[i].Priority != sources[j].Priority {
+			return sources[i].Priority > sources[j].Priority
+		}
+		return sources[i].Name < sources[j].Name
+	})
+}
+
+// indexCacheDir : cached index files of git and http sources
+func indexCacheDir() string {
+	return filepath.Join(paths.CacheDir(), "index")
+}
+
+// CachedIndex : path of the cached index of a git or http source, or ""
+// if it was never fetched
+func (s Source) CachedIndex() string {
+	for _, ext := range []string{".toml", ".json"} {
+		p := filepath.Join(indexCacheDir(), s.Name+ext)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// indexFiles : index files the source currently provides
+func (s Source) indexFiles() []string {
+	switch s.Type {
+	case SourceBuiltin:
+		return nil
+	case SourceDir:
+		return registryFilesIn(s.URL)
+	}
+	if index := s.CachedIndex(); index != "" {
+		return []string{index}
+	}
+	return nil
+}
+
+// load : entries provided by the source. Git and http sources are read
+// from the local index cache, so loading never touches the network. Index
+// files of configured sources that fail signature checks are refused.
+func (s Source) load() (entries []Dotfile, errs []error) {
+	if s.Type == SourceBuiltin {
+		return append([]Dotfile(nil), Registry...), nil
+	}
+	if s.Type != SourceDir && s.CachedIndex() == "" {
+		return nil, []error{fmt.Errorf("source %s has no cached index; run 'hypr-release registry update'", s.Name)}
+	}
+	if _, err := os.Stat(s.URL); s.Type == SourceDir && err != nil {
+		if !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+		return nil, errs
+	}
+
+	var keys []TrustedKey
+	if !s.local {
+		var err error
+		if keys, err = TrustedKeys(); err != nil {
+			return nil, []error{err}
+		}
+	}
+	for _, file := range s.indexFiles() {
+		// the bytes that were verified are the bytes that are parsed
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var signer string
+		if !s.local {
+			if signer, err = verifySourceFile(s, file, data, keys); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		loaded, err := parseRegistryFile(file, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, d := range loaded {
+			d.SignedBy = signer
+			entries = mergeEntry(entries, d)
+		}
+	}
+	return entries, errs
+}
+
+// registryFilesIn : *.toml and *.json files of dir, sorted by name
+func registryFilesIn(dir string) []string {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, f := range files {
+		ext := strings.ToLower(filepath.Ext(f.Name()))
+		if !f.IsDir() && (ext == ".toml" || ext == ".json") {
+			out = append(out, filepath.Join(dir, f.Name()))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// VerifySource : ids of the trusted keys that signed the source's index
+// files. Built-in sources return nil; unsigned files of a source that
+// allows them are skipped.
+func VerifySource(s Source) ([]string, error) {
+	if s.local {
+		return nil, nil
+	}
+	return verifySource(s)
+}
+
+// SourceStatus : outcome of refreshing one source
+type SourceStatus struct {
+	Source   Source    `json:"source"`
+	Entries  int       `json:"entries"`
+	SignedBy []string  `json:"signed_by,omitempty"`
+	Updated  time.Time `json:"updated"`
+	Err      error     `json:"-"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// UpdateSources : refreshes the index cache of every git and http source
+// and re-checks dir sources. One failing source does not stop the others.
+func UpdateSources() ([]SourceStatus, error) {
+	sources, err := Sources()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(indexCacheDir(), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create index cache: %v", err)
+	}
+
+	var statuses []SourceStatus
+	for _, s := range sources {
+		if s.Type == SourceBuiltin {
+			continue
+		}
+		st := SourceStatus{Source: s, Updated: time.Now()}
+		switch s.Type {
+		case SourceGit:
+			st.Err = fetchGitIndex(s)
+		case SourceHTTP:
+			st.Err = fetchHTTPIndex(s)
+		}
+		if st.Err == nil {
+			st.SignedBy, st.Err = VerifySource(s)
+		}
+		if st.Err == nil {
+			entries, errs := s.load()
+			st.Entries = len(entries)
+			if len(errs) > 0 {
+				st.Err = errs[0]
+			}
+		}
+		if st.Err != nil {
+			st.Error = st.Err.Error()
+		}
+		statuses = append(statuses, st)
+	}
+	Reload()
+	return statuses, nil
+}
+
+// fetchGitIndex : shallow clone (or fetch) of the source repository under
+// the cache, then copies its index file into the index cache
+func fetchGitIndex(s Source) error {
+	ctx, gb := context.Background(), gitbackend.Default()
+	repo := filepath.Join(paths.CacheDir(), "sources", s.Name)
+	if _, err := os.Stat(filepath.Join(repo, ".git")); err != nil {
+		os.RemoveAll(repo)
+		if err := gb.Clone(ctx, s.URL, repo, gitbackend.CloneOptions{Branch: s.Branch, Depth: 1}); err != nil {
+			return fmt.Errorf("git clone %s failed: %v", s.URL, err)
+		}
+	} else {
+		// without a configured branch, stay on the one the clone checked out
+		branch := s.Branch
+		if branch == "" {
+			var err error
+			if branch, err = gb.CurrentBranch(ctx, repo); err != nil || branch == "HEAD" {
+				return fmt.Errorf("source %s: cannot tell which branch to update; set branch", s.Name)
+			}
+		}
+		if err := gb.Fetch(ctx, repo, gitbackend.FetchOptions{URL: s.URL}); err != nil {
+			return fmt.Errorf("git fetch %s failed: %v", s.URL, err)
+		}
+		if err := gb.Checkout(ctx, repo, "refs/remotes/origin/"+branch, branch); err != nil {
+			return fmt.Errorf("git reset failed: %v", err)
+		}
+	}
+
+	candidates := []string{"index.toml", "index.json"}
+	if s.Index != "" {
+		candidates = []string{s.Index}
+	}
+	for _, name := range candidates {
+		src := filepath.Join(repo, name)
+		data, err := os.ReadFile(src)
+		if err != nil {
+			continue
+		}
+		sig, _ := os.ReadFile(src + SigSuffix)
+		return storeIndex(s, filepath.Ext(name), data, sig)
+	}
+	return fmt.Errorf("source %s: no %s in repository", s.Name, strings.Join(candidates, " or "))
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func fetchHTTPIndex(s Source) error {
+	resp, err := httpClient.Get(s.URL)
+	if err != nil {
+		return fmt.Errorf("download failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad response from %s: %s", s.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return fmt.Errorf("download failed: %v", err)
+	}
+
+	sig, err := fetchHTTPSignature(s.URL)
+	if err != nil {
+		return err
+	}
+
+	ext := strings.ToLower(path.Ext(strings.SplitN(s.URL, "?", 2)[0]))
+	if ext != ".toml" && ext != ".json" {
+		ext = ".json"
+		if ct := resp.Header.Get("Content-Type"); strings.Contains(ct, "toml") {
+			ext = ".toml"
+		}
+	}
+	return storeIndex(s, ext, data, sig)
+}
+
+// fetchHTTPSignature : <url>.sig next to an http index; nil if there is none
+func fetchHTTPSignature(indexURL string) ([]byte, error) {
+	parts := strings.SplitN(indexURL, "?", 2)
+	sigURL := parts[0] + SigSuffix
+	if len(parts) == 2 {
+		sigURL += "?" + parts[1]
+	}
+	resp, err := httpClient.Get(sigURL)
+	if err != nil {
+		return nil, fmt.Errorf("signature download failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad response from %s: %s", sigURL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4096))
+}
+
+// storeIndex : validates data as a registry file, verifies its signature
+// and atomically replaces the cached index; a broken or untrusted download
+// never replaces a good cache
+func storeIndex(s Source, ext string, data, sig []byte) error {
+	keys, err := TrustedKeys()
+	if err != nil {
+		return err
+	}
+	if _, err := VerifyIndex(data, sig, s.allowedKeys(keys)); err != nil && !s.unsignedAllowed() {
+		return fmt.Errorf("source %s: %v (set allow_unsigned = true for the source or %s=1 to accept)", s.Name, err, AllowUnsignedEnv)
+	}
+
+	tmp, err := os.CreateTemp(indexCacheDir(), s.Name+"-*"+ext)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+	if _, err := LoadRegistryFile(tmp.Name()); err != nil {
+		return fmt.Errorf("source %s: invalid index: %v", s.Name, err)
+	}
+
+	for _, old := range []string{".toml", ".json"} {
+		os.Remove(filepath.Join(indexCacheDir(), s.Name+old))
+		os.Remove(filepath.Join(indexCacheDir(), s.Name+old+SigSuffix))
+	}
+	dest := filepath.Join(indexCacheDir(), s.Name+ext)
+	if len(sig) > 0 {
+		if err := os.WriteFile(dest+SigSuffix, sig, 0644); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), dest)
+}
@@ -0,0 +1,297 @@
+package summaryofversion
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+)
+
+// isolate : points the XDG directories at a temporary home. Sources and
+// keys under /etc and /usr/share cannot be redirected, so machines that
+// have them skip the test.
+func isolate(t *testing.T) {
+	t.Helper()
+	for _, dir := range []string{"/etc/hypr-release", "/usr/share/hypr-release"} {
+		if _, err := os.Stat(dir); err == nil {
+			t.Skipf("%s exists and would leak into the test", dir)
+		}
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv(AllowUnsignedEnv, "")
+	Reload()
+	t.Cleanup(Reload)
+}
+
+// writeFile : writes data to path, creating its directory
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// trustKey : new signing key, trusted under id
+func trustKey(t *testing.T, id string) ed25519.PrivateKey {
+	t.Helper()
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(paths.ConfigDir(), "trusted-keys.d", id+".pub"), string(pub))
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(priv)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ed25519.PrivateKey(raw)
+}
+
+func writeSources(t *testing.T, data string) {
+	t.Helper()
+	writeFile(t, filepath.Join(paths.ConfigDir(), "sources.toml"), data)
+}
+
+// entry : a registry entry in TOML
+func entry(name, description string) string {
+	return "[[dotfile]]\nname = \"" + name + "\"\nrepo = \"https://git.example.com/" + strings.ToLower(name) + ".git\"\ndescription = \"" + description + "\"\n"
+}
+
+func find(entries []Dotfile, name string) *Dotfile {
+	for i := range entries {
+		if strings.EqualFold(entries[i].Name, name) {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+func statusOf(t *testing.T, statuses []SourceStatus, name string) SourceStatus {
+	t.Helper()
+	for _, st := range statuses {
+		if st.Source.Name == name {
+			return st
+		}
+	}
+	t.Fatalf("no status for source %s", name)
+	return SourceStatus{}
+}
+
+func TestFetchHTTPIndex(t *testing.T) {
+	isolate(t)
+	key := trustKey(t, "team")
+
+	index := entry("TeamDots", "signed over http")
+	sig := string(SignIndex(key, []byte(index)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/registry/index.toml":
+			w.Write([]byte(index))
+		case "/registry/index.toml.sig":
+			if sig == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(sig))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	writeSources(t, "[[source]]\nname = \"team\"\ntype = \"http\"\nurl = \""+srv.URL+"/registry/index.toml\"\n")
+
+	statuses, err := UpdateSources()
+	if err != nil {
+		t.Fatalf("UpdateSources: %v", err)
+	}
+	st := statusOf(t, statuses, "team")
+	if st.Err != nil || st.Entries != 1 || len(st.SignedBy) != 1 || st.SignedBy[0] != "team" {
+		t.Fatalf("team status = %+v", st)
+	}
+	cached := filepath.Join(paths.CacheDir(), "index", "team.toml")
+	if _, err := os.Stat(cached + SigSuffix); err != nil {
+		t.Errorf("signature not cached next to %s: %v", cached, err)
+	}
+	entries, errs := LoadRegistry()
+	if len(errs) > 0 {
+		t.Fatalf("LoadRegistry: %v", errs)
+	}
+	d := find(entries, "TeamDots")
+	if d == nil || d.Source != "team" || d.SignedBy != "team" || d.Branch != DefaultBranch {
+		t.Fatalf("TeamDots = %+v", d)
+	}
+
+	// a tampered download is refused and the good cache stays
+	index = entry("TeamDots", "tampered")
+	statuses, _ = UpdateSources()
+	if st := statusOf(t, statuses, "team"); st.Err == nil {
+		t.Errorf("tampered index accepted")
+	}
+	entries, _ = LoadRegistry()
+	if d := find(entries, "TeamDots"); d == nil || d.Description != "signed over http" {
+		t.Errorf("cache replaced by tampered index: %+v", d)
+	}
+
+	// unsigned indexes need allow_unsigned
+	sig = ""
+	statuses, _ = UpdateSources()
+	if st := statusOf(t, statuses, "team"); st.Err == nil {
+		t.Errorf("unsigned index accepted")
+	}
+	writeSources(t, "[[source]]\nname = \"team\"\ntype = \"http\"\nurl = \""+srv.URL+"/registry/index.toml\"\nallow_unsigned = true\n")
+	statuses, _ = UpdateSources()
+	if st := statusOf(t, statuses, "team"); st.Err != nil || len(st.SignedBy) != 0 {
+		t.Errorf("unsigned index with allow_unsigned: %+v", st)
+	}
+	entries, _ = LoadRegistry()
+	if d := find(entries, "TeamDots"); d == nil || d.Description != "tampered" || d.SignedBy != "" {
+		t.Errorf("TeamDots after unsigned update = %+v", d)
+	}
+}
+
+func TestFetchGitIndex(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	isolate(t)
+	t.Setenv("HYPR_RELEASE_GIT_BACKEND", "exec")
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(env, "test")
+	}
+	for _, env := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(env, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	key := trustKey(t, "team")
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	publish := func(index string) {
+		t.Helper()
+		writeFile(t, filepath.Join(repo, "index.toml"), index)
+		writeFile(t, filepath.Join(repo, "index.toml.sig"), string(SignIndex(key, []byte(index))))
+		git("add", "-A")
+		git("commit", "-q", "-m", "publish")
+	}
+	git("init", "-q", "-b", "trunk")
+	publish(entry("GitDots", "first"))
+	writeSources(t, "[[source]]\nname = \"team\"\ntype = \"git\"\nurl = \"file://"+repo+"\"\n")
+
+	statuses, err := UpdateSources()
+	if err != nil {
+		t.Fatalf("UpdateSources: %v", err)
+	}
+	if st := statusOf(t, statuses, "team"); st.Err != nil || st.Entries != 1 {
+		t.Fatalf("team status after clone = %+v", st)
+	}
+
+	// the second update fetches into the existing clone
+	publish(entry("GitDots", "second") + entry("MoreDots", "added"))
+	statuses, _ = UpdateSources()
+	if st := statusOf(t, statuses, "team"); st.Err != nil || st.Entries != 2 {
+		t.Fatalf("team status after fetch = %+v", st)
+	}
+	entries, errs := LoadRegistry()
+	if len(errs) > 0 {
+		t.Fatalf("LoadRegistry: %v", errs)
+	}
+	if d := find(entries, "GitDots"); d == nil || d.Description != "second" || d.SignedBy != "team" {
+		t.Errorf("GitDots = %+v", d)
+	}
+	if find(entries, "MoreDots") == nil {
+		t.Errorf("MoreDots missing after fetch")
+	}
+}
+
+func TestSourcePriority(t *testing.T) {
+	isolate(t)
+	base := t.TempDir()
+	for dir, index := range map[string]string{
+		"low":  entry("Shared", "low") + entry("OnlyLow", "low"),
+		"high": entry("Shared", "high") + entry("HyDE", "high"),
+	} {
+		writeFile(t, filepath.Join(base, dir, "index.toml"), index)
+	}
+	writeFile(t, filepath.Join(paths.ConfigDir(), "registry.d", "user.toml"), entry("Shared", "user")+entry("hyde", "user"))
+	writeSources(t, ""+
+		"[[source]]\nname = \"low\"\ntype = \"dir\"\nurl = \""+filepath.Join(base, "low")+"\"\npriority = 30\nallow_unsigned = true\n"+
+		"[[source]]\nname = \"high\"\ntype = \"dir\"\nurl = \""+filepath.Join(base, "high")+"\"\npriority = 95\nallow_unsigned = true\n")
+
+	sources, err := Sources()
+	if err != nil {
+		t.Fatalf("Sources: %v", err)
+	}
+	var order []string
+	for _, s := range sources {
+		order = append(order, s.Name)
+	}
+	if got, want := strings.Join(order, ","), "high,user,low,system,vendor,builtin"; got != want {
+		t.Errorf("Sources order = %s, want %s", got, want)
+	}
+
+	entries, errs := LoadRegistry()
+	if len(errs) > 0 {
+		t.Fatalf("LoadRegistry: %v", errs)
+	}
+	for name, want := range map[string]string{
+		"Shared":  "high", // high (95) beats user (90) and low (30)
+		"OnlyLow": "low",
+		"HyDE":    "high", // names match case-insensitively across sources
+	} {
+		d := find(entries, name)
+		if d == nil || d.Source != want || d.Description != want {
+			t.Errorf("%s = %+v, want it from %s", name, d, want)
+		}
+	}
+	// the built-in order is kept for overridden built-in entries
+	if entries[0].Name != "HyDE" {
+		t.Errorf("first entry = %s, want HyDE", entries[0].Name)
+	}
+	if n := len(entries); n != len(Registry)+2 {
+		t.Errorf("%d entries, want %d (built-in + Shared + OnlyLow)", n, len(Registry)+2)
+	}
+}
+
+func TestSourcePriorityTie(t *testing.T) {
+	isolate(t)
+	base := t.TempDir()
+	for _, name := range []string{"beta", "alpha"} {
+		writeFile(t, filepath.Join(base, name, "index.toml"), entry("Tie", name))
+	}
+	// default priority (50) for both; beta is configured first
+	writeSources(t, ""+
+		"[[source]]\nname = \"beta\"\ntype = \"dir\"\nurl = \""+filepath.Join(base, "beta")+"\"\nallow_unsigned = true\n"+
+		"[[source]]\nname = \"alpha\"\ntype = \"dir\"\nurl = \""+filepath.Join(base, "alpha")+"\"\nallow_unsigned = true\n")
+
+	entries, errs := LoadRegistry()
+	if len(errs) > 0 {
+		t.Fatalf("LoadRegistry: %v", errs)
+	}
+	if d := find(entries, "Tie"); d == nil || d.Source != "alpha" {
+		t.Errorf("Tie = %+v, want it from alpha (name sorts first)", d)
+	}
+
+	// within one source, a later file replaces an earlier entry
+	writeFile(t, filepath.Join(base, "alpha", "zz-override.toml"), entry("tie", "override"))
+	entries, _ = LoadRegistry()
+	if d := find(entries, "Tie"); d == nil || d.Description != "override" {
+		t.Errorf("Tie = %+v, want the entry of zz-override.toml", d)
+	}
+}
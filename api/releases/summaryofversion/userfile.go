@@ -0,0 +1,102 @@
+package summaryofversion
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ErrEntryExists : the user registry file already has an entry with the name
+var ErrEntryExists = errors.New("entry already exists in the user registry")
+
+// EncodeEntry : TOML [[dotfile]] table for d, as written to registry files
+func EncodeEntry(d Dotfile) (string, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(registryFile{Dotfiles: []Dotfile{d}}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// AddUserEntry : validates d and adds it to UserRegistryFile. A new entry is
+// appended so comments in the file survive; replacing an existing entry
+// (replace = true) rewrites the file. The cached registry is reloaded.
+func AddUserEntry(d Dotfile, replace bool) (string, error) {
+	if d.Branch == "" {
+		d.Branch = DefaultBranch
+	}
+	d.Source = ""
+	d.SignedBy = ""
+	if err := Validate(d); err != nil {
+		return "", err
+	}
+	path := UserRegistryFile()
+
+	var existing []Dotfile
+	if _, err := os.Stat(path); err == nil {
+		if existing, err = LoadRegistryFile(path); err != nil {
+			return "", err
+		}
+	}
+	found := false
+	for i := range existing {
+		if strings.EqualFold(existing[i].Name, d.Name) {
+			if !replace {
+				return "", fmt.Errorf("%s: %w", d.Name, ErrEntryExists)
+			}
+			existing[i] = d
+			found = true
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if found {
+		var buf bytes.Buffer
+		buf.WriteString("# hypr-release user registry\n\n")
+		for _, e := range existing {
+			block, err := EncodeEntry(e)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(block + "\n")
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+			return "", err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return "", err
+		}
+	} else {
+		block, err := EncodeEntry(d)
+		if err != nil {
+			return "", err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return "", err
+		}
+		if len(existing) > 0 {
+			block = "\n" + block
+		}
+		_, werr := f.WriteString(block)
+		if cerr := f.Close(); werr == nil {
+			werr = cerr
+		}
+		if werr != nil {
+			return "", werr
+		}
+	}
+
+	Reload()
+	return path, nil
+}
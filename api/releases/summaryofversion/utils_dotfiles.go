@@ -1,11 +1,39 @@
 package summaryofversion
 
-// GetDotfileByName returns a pointer to the Dotfile with the given name.
+import (
+	"fmt"
+	"strings"
+)
+
+// GetDotfileByName returns a pointer to the Dotfile with the given name
+// from the merged registry (see All). Names are matched case-insensitively,
+// as everywhere else in the registry.
 func GetDotfileByName(name string) *Dotfile {
-	for _, d := range Registry {
-		if d.Name == name {
+	for _, d := range All() {
+		if strings.EqualFold(d.Name, name) {
 			return &d
 		}
 	}
 	return nil
 }
+
+// Suggest returns registry names that fuzzily match name, best first; used
+// for "did you mean" hints when a lookup fails.
+func Suggest(name string) []string {
+	var names []string
+	for _, r := range Search(name, Filter{}) {
+		if r.Match != "text" && len(names) < 3 {
+			names = append(names, r.Dotfile.Name)
+		}
+	}
+	return names
+}
+
+// NotFoundError returns the error used when name is not in the registry,
+// with suggestions when there are close matches.
+func NotFoundError(name string) error {
+	if s := Suggest(name); len(s) > 0 {
+		return fmt.Errorf("dotfile '%s' not found in registry (did you mean %s?)", name, strings.Join(s, ", "))
+	}
+	return fmt.Errorf("dotfile '%s' not found in registry", name)
+}
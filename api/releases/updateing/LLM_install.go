@@ -1,7 +1,6 @@
 package updateing
 
 import (
-	"bufio"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -11,6 +10,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/prompt"
 )
 
 // LLMModel : information about available language models
@@ -36,7 +37,7 @@ func CheckOrInstallWingman() error {
 	fmt.Println("↪  Attempting to install automatically...")
 
 	// Kullanıcıya bilgi notu
-	fmt.Println(`
+	fmt.Print(`
 You can manually install Wingman using one of the following:
   • Arch Linux (AUR):    yay -S wingman-bin
   • Go source install:   go install github.com/adrianliechti/wingman/cmd/wingman@latest
@@ -140,29 +141,27 @@ func ListAvailableModels() []LLMModel {
 }
 
 // SelectAndInstallModel : lets the user choose an LLM to install interactively
-func SelectAndInstallModel() {
+func SelectAndInstallModel(p prompt.Prompter) {
 	models := ListAvailableModels()
 
 	fmt.Println("Available LLM models for HyprRelease AI:")
 	fmt.Println("----------------------------------------")
+	options := make([]string, len(models))
 	for i, m := range models {
-		fmt.Printf("[%d] %s  (%s)\n    %s\n", i+1, m.Name, m.Size, m.Desc)
+		options[i] = fmt.Sprintf("%s  (%s) - %s", m.Name, m.Size, m.Desc)
 	}
-	fmt.Println("----------------------------------------")
-
-	fmt.Print("Select model number to install: ")
-	reader := bufio.NewReader(os.Stdin)
-	choiceRaw, _ := reader.ReadString('\n')
-	choiceRaw = strings.TrimSpace(choiceRaw)
 
-	var index int
-	fmt.Sscanf(choiceRaw, "%d", &index)
-	if index <= 0 || index > len(models) {
+	index, err := p.Select(PromptModelSelect, "Select model number to install", options)
+	if err != nil {
+		fmt.Println("Invalid selection:", err)
+		return
+	}
+	if index < 0 || index >= len(models) {
 		fmt.Println("Invalid selection.")
 		return
 	}
 
-	selected := models[index-1]
+	selected := models[index]
 	fmt.Printf("\nYou selected: %s (%s)\n", selected.Name, selected.Size)
 
 	path := filepath.Join(SystemModelDir, selected.Name)
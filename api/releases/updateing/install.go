@@ -2,108 +2,243 @@ package updateing
 
 import (
 	"fmt"
-	"io"
-        "bufio"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
-        "github.com/hyprcommunity/hypr-release/api/releases/summaryofversion" 
+
+	"github.com/hyprcommunity/hypr-release/api/releases/mirror"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+	"github.com/hyprcommunity/hypr-release/api/releases/version"
 )
 
 // const SystemModelDir = "/usr/share/hypr-release/ai/LLM/"
 
 // InstallFromRegistry : summaryofversion/registry.go'dan dotfile indirip kurar
-func InstallFromRegistry(name string) error {
-	var selected *summaryofversion.Dotfile
-	for _, d := range summaryofversion.Registry {
-		if strings.EqualFold(d.Name, name) {
-			selected = &d
-			break
-		}
+func InstallFromRegistry(name string, opts Options) error {
+	selected, requested, err := resolveEntry(name, opts)
+	if err != nil {
+		return err
 	}
 
-	if selected == nil {
-		return fmt.Errorf("dotfile '%s' not found in registry", name)
-	}
+	fmt.Fprintf(opts.out(), "[hyprrelease] selected: %s (%s)\n", selected.Name, selected.Repo)
+	fmt.Fprintf(opts.out(), "[hyprrelease] default branch: %s\n", selected.Branch)
+	warnCompatibility(*selected, opts)
 
-	fmt.Printf("[hyprrelease] selected: %s (%s)\n", selected.Name, selected.Repo)
-	fmt.Printf("[hyprrelease] default branch: %s\n", selected.Branch)
+	// Kullanıcıya farklı branch seçme fırsatı ver (--ref / --at verilmediyse)
+	ref := strings.TrimSpace(opts.Ref)
+	if ref == "" && opts.At.IsZero() {
+		ref, err = opts.input(PromptInstallRef,
+			"Enter a branch, tag or commit to install (leave empty to use default)", "")
+		if err != nil {
+			return fmt.Errorf("branch selection failed: %w", err)
+		}
+		ref = strings.TrimSpace(ref)
+	}
 
-	// Kullanıcıya farklı branch seçme fırsatı ver
-	fmt.Print("Enter a branch or tag to install (leave empty to use default): ")
-	reader := bufio.NewReader(os.Stdin)
-	userBranch, _ := reader.ReadString('\n')
-	userBranch = strings.TrimSpace(userBranch)
+	if ref != "" {
+		fmt.Fprintf(opts.out(), "[hyprrelease] overriding branch: %s → %s\n", selected.Branch, ref)
+	} else {
+		fmt.Fprintf(opts.out(), "[hyprrelease] using default branch: %s\n", selected.Branch)
+		ref = selected.Branch
+	}
+	if !opts.At.IsZero() {
+		fmt.Fprintf(opts.out(), "[hyprrelease] selecting the last commit on %s before %s\n", ref, opts.At.Format("2006-01-02 15:04"))
+	}
 
-	if userBranch != "" {
-		fmt.Printf("[hyprrelease] overriding branch: %s → %s\n", selected.Branch, userBranch)
-		selected.Branch = userBranch
+	var targetDir, commit string
+	if opts.dryRun() {
+		if targetDir, commit, err = planCheckout(*selected, ref, opts); err != nil || targetDir == "" {
+			return err
+		}
 	} else {
-		fmt.Printf("[hyprrelease] using default branch: %s\n", selected.Branch)
+		fmt.Fprintf(opts.out(), "[hyprrelease] syncing %s (ref: %s)...\n", selected.Repo, ref)
+		targetDir, err = mirror.Sync(*selected, ref, mirror.Options{Offline: opts.Offline, Out: opts.out(), At: opts.At})
+		if err != nil {
+			return err
+		}
+		commit, err = mirror.Head(targetDir)
+		if err != nil {
+			return fmt.Errorf("cannot resolve the checked out commit: %v", err)
+		}
+	}
+	subs, err := mirror.Submodules(targetDir)
+	if err != nil {
+		fmt.Fprintln(opts.out(), "⚠️", err)
+	}
+	for _, sub := range subs {
+		fmt.Fprintf(opts.out(), "[hyprrelease] submodule %s at %s\n", sub.Path, shortSHA(sub.Commit))
+	}
+	if opts.dryRun() {
+		checkout := fmt.Sprintf("git fetch origin && git checkout %s", commit)
+		if len(subs) > 0 {
+			checkout += " && git submodule update --init --recursive --depth=1"
+		}
+		opts.Plan.AddCommand(plan.OriginGit, targetDir, checkout)
 	}
 
-	targetDir := filepath.Join(os.TempDir(), "hyprrelease-dotfiles", selected.Name)
-	os.RemoveAll(targetDir)
-	os.MkdirAll(targetDir, 0755)
+	fmt.Fprintf(opts.out(), "[hyprrelease] repository ready: %s (commit %s)\n", targetDir, shortSHA(commit))
+	opts.Installer = selected.Installer
+	opts.Dotfile = selected.Name
+	if err := InstallRepo(targetDir, opts); err != nil {
+		return err
+	}
+	migrated := true
+	if requested != nil {
+		if migrated, err = offerMetadataMigration(*requested, *selected, opts); err != nil {
+			return err
+		}
+	}
+	if err := recordInstall(*selected, ref, commit, migrated, opts); err != nil {
+		fmt.Fprintln(opts.out(), "⚠️", err)
+	}
+	return nil
+}
 
-	fmt.Printf("[hyprrelease] cloning %s (branch: %s)...\n", selected.Repo, selected.Branch)
-	cmd := exec.Command("git", "clone", "--depth=1", "-b", selected.Branch, selected.Repo, targetDir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone: %v", err)
+// planCheckout : dry-run stand-in for mirror.Sync. The repository cache is
+// neither fetched nor checked out: the commit is resolved from the last
+// fetch, and the files are listed from the cached checkout. Without a
+// cached copy only the clone is planned and the returned dir is empty.
+func planCheckout(d summaryofversion.Dotfile, ref string, opts Options) (dir, commit string, err error) {
+	dir = mirror.Path(d.Name)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		opts.Plan.AddCommand(plan.OriginGit, "", fmt.Sprintf("git clone %s %s && git checkout %s", d.Repo, dir, ref))
+		opts.Plan.Note("no cached copy of %s: its files cannot be listed without fetching (run 'hypr-release cache update %s' first)", d.Name, d.Name)
+		return "", "", nil
+	}
+	commit, err = mirror.Resolve(d, ref, opts.At)
+	if err != nil {
+		return "", "", err
+	}
+	opts.Plan.Note("the remote was not fetched; %s is resolved from the cache as last fetched", ref)
+	if head, err := mirror.Head(dir); err == nil && head != commit {
+		opts.Plan.Note("files are listed from the cached checkout at %s, not %s", shortSHA(head), shortSHA(commit))
 	}
+	return dir, commit, nil
+}
 
-	fmt.Println("[hyprrelease] repository cloned successfully")
-	return InstallRepo(targetDir)
+// warnCompatibility : warns when the registry metadata says the dotfile
+// does not support this distro or the installed Hyprland version
+func warnCompatibility(d summaryofversion.Dotfile, opts Options) {
+	var warnings []string
+	if host := summaryofversion.HostDistros(); len(d.Distros) > 0 && len(host) > 0 && !d.SupportsDistro(host...) {
+		warnings = append(warnings, fmt.Sprintf("%s lists distros %s; this system is %s",
+			d.Name, strings.Join(d.Distros, ", "), strings.Join(host, "/")))
+	}
+	if r := d.HyprlandRange(); r != "" {
+		if v := localHyprlandVersion(); !d.SupportsHyprland(v) {
+			warnings = append(warnings, fmt.Sprintf("%s supports Hyprland %s; installed version is %s", d.Name, r, v))
+		}
+	}
+	if len(d.Packages) > 0 {
+		fmt.Fprintf(opts.out(), "[hyprrelease] required packages: %s\n", strings.Join(d.Packages, " "))
+	}
+	for _, w := range warnings {
+		fmt.Fprintln(opts.out(), "⚠️", w)
+		if opts.dryRun() {
+			opts.Plan.Note("%s", w)
+		}
+	}
 }
+
+// localHyprlandVersion : installed Hyprland version, zero if unknown
+func localHyprlandVersion() version.Version {
+	for _, bin := range []string{"Hyprland", "hyprland"} {
+		if out, err := exec.Command(bin, "--version").Output(); err == nil {
+			v, _ := version.ParseBanner(string(out))
+			return v
+		}
+	}
+	return version.Version{}
+}
+
 // ------------------------------------------------------------
 // InstallRepo : akıllı kurulum (betik, README, AI-safe kopya)
-func InstallRepo(repoPath string) error {
-	fmt.Println("[hyprrelease] starting intelligent installation")
+func InstallRepo(repoPath string, opts Options) error {
+	fmt.Fprintln(opts.out(), "[hyprrelease] starting intelligent installation")
 
 	// 1️⃣ install.sh veya hyprrelease.sh varsa çalıştır
-	if err := runInstallerScript(repoPath); err == nil {
-		return nil
+	if findInstallerScript(repoPath, opts) != "" {
+		if err := runTracked(repoPath, opts, func() error { return runInstallerScript(repoPath, opts) }); err == nil {
+			return nil
+		}
 	}
 
 	// 2️⃣ README varsa AI analizli kurulum
 	readme := findReadme(repoPath)
 	if readme != "" {
-		if err := installFromReadme(readme, repoPath); err == nil {
+		err := runTracked(repoPath, opts, func() error { return installFromReadme(readme, repoPath, opts) })
+		if err == nil {
 			return nil
 		}
+		if isPromptError(err) {
+			return err
+		}
 	}
 
-	// 3️⃣ fallback: AI dosya seçimiyle güvenli kopyalama
-	if err := aiSafeFileInstall(repoPath); err != nil {
-		fmt.Println("⚠️ AI safe-copy failed, using default safe filter.")
-		if err2 := defaultCopy(repoPath); err2 != nil {
+	// 3️⃣ fallback: AI dosya seçimiyle güvenli kopyalama; dry-run'da model
+	// çağrılmaz, varsayılan filtrenin dosyaları planlanır
+	if opts.dryRun() {
+		opts.Plan.Note("files are selected by the model helper (wingman) when installing; the plan lists the default filter's files")
+		return defaultCopy(repoPath, opts)
+	}
+	if err := aiSafeFileInstall(repoPath, opts); err != nil {
+		// cevapsız kalan soru varsa varsayılan kopyaya düşme
+		if isPromptError(err) {
+			return err
+		}
+		fmt.Fprintln(opts.out(), "⚠️ AI safe-copy failed, using default safe filter.")
+		if err2 := defaultCopy(repoPath, opts); err2 != nil {
 			return fmt.Errorf("fallback copy failed: %v", err2)
 		}
 	}
-	fmt.Println("[hyprrelease] installation complete")
+	fmt.Fprintln(opts.out(), "[hyprrelease] installation complete")
 	return nil
 }
 
 // ------------------------------------------------------------
 // install.sh veya hyprrelease.sh
-func runInstallerScript(repoPath string) error {
-	scripts := []string{"hyprrelease.sh", "install.sh"}
-	for _, s := range scripts {
+// InstallerScripts : installer scripts looked for in a repository root, in
+// order; a registry entry's installer field is tried before these
+var InstallerScripts = []string{"hyprrelease.sh", "install.sh"}
+
+func installerScripts(opts Options) []string {
+	if opts.Installer == "" {
+		return InstallerScripts
+	}
+	return append([]string{opts.Installer}, InstallerScripts...)
+}
+
+func findInstallerScript(repoPath string, opts Options) string {
+	for _, s := range installerScripts(opts) {
+		if _, err := os.Stat(filepath.Join(repoPath, s)); err == nil {
+			return s
+		}
+	}
+	return ""
+}
+
+func runInstallerScript(repoPath string, opts Options) error {
+	for _, s := range installerScripts(opts) {
 		script := filepath.Join(repoPath, s)
 		if _, err := os.Stat(script); err == nil {
-			fmt.Printf("[hyprrelease] running %s\n", s)
+			if opts.dryRun() {
+				opts.Plan.AddCommand(plan.OriginScript, repoPath, fmt.Sprintf("bash %s install", script))
+				opts.Plan.Note("if %s fails, the next installer script, the README and then file copy are tried", s)
+				return nil
+			}
+			fmt.Fprintf(opts.out(), "[hyprrelease] running %s\n", s)
 			cmd := exec.Command("bash", script, "install")
-			cmd.Stdout = os.Stdout
+			cmd.Stdout = opts.out()
 			cmd.Stderr = os.Stderr
 			if err := cmd.Run(); err != nil {
-				fmt.Println("⚠️ script failed:", err)
+				fmt.Fprintln(opts.out(), "⚠️ script failed:", err)
 				continue
 			}
-			fmt.Println("[hyprrelease] script install complete")
+			fmt.Fprintln(opts.out(), "[hyprrelease] script install complete")
 			return nil
 		}
 	}
@@ -112,172 +247,181 @@ func runInstallerScript(repoPath string) error {
 
 // ------------------------------------------------------------
 // README analizli kurulum
-func installFromReadme(readmePath, repoPath string) error {
-    // 🔧 README içeriğini oku
-    content, err := os.ReadFile(readmePath)
-    if err != nil {
-        return fmt.Errorf("failed to read README: %w", err)
-    }
-
-    // 🔍 Model dizininden .gguf dosyasını bul
-    files, err := os.ReadDir(SystemModelDir)
-    if err != nil {
-        fmt.Printf("⚠️ cannot read model directory: %v\n", err)
-        fmt.Println("↪ fallback to regex parser.")
-        return parseReadmeRegex(string(content))
-    }
-
-    var modelPath string
-    for _, f := range files {
-        if !f.IsDir() && strings.HasSuffix(f.Name(), ".gguf") {
-            modelPath = filepath.Join(SystemModelDir, f.Name())
-            break
-        }
-    }
-
-    // 🔄 Model bulunamadıysa regex parser’a geç
-    if modelPath == "" {
-        fmt.Println("⚠️ no LLM model found, fallback to regex parser.")
-        return parseReadmeRegex(string(content))
-    }
-
-    fmt.Printf("[hyprrelease] using Wingman with model: %s\n", filepath.Base(modelPath))
-
-    // 🧠 Wingman prompt
-    prompt := `
+func installFromReadme(readmePath, repoPath string, opts Options) error {
+	// 🔧 README içeriğini oku
+	content, err := os.ReadFile(readmePath)
+	if err != nil {
+		return fmt.Errorf("failed to read README: %w", err)
+	}
+
+	if opts.dryRun() {
+		opts.Plan.Note("README steps are extracted by the model helper (wingman) when installing; the plan lists what the regex parser finds")
+		return parseReadmeRegex(string(content), opts)
+	}
+
+	// 🔍 Model dizininden .gguf dosyasını bul
+	files, err := os.ReadDir(SystemModelDir)
+	if err != nil {
+		fmt.Fprintf(opts.out(), "⚠️ cannot read model directory: %v\n", err)
+		fmt.Fprintln(opts.out(), "↪ fallback to regex parser.")
+		return parseReadmeRegex(string(content), opts)
+	}
+
+	var modelPath string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".gguf") {
+			modelPath = filepath.Join(SystemModelDir, f.Name())
+			break
+		}
+	}
+
+	// 🔄 Model bulunamadıysa regex parser’a geç
+	if modelPath == "" {
+		fmt.Fprintln(opts.out(), "⚠️ no LLM model found, fallback to regex parser.")
+		return parseReadmeRegex(string(content), opts)
+	}
+
+	fmt.Fprintf(opts.out(), "[hyprrelease] using Wingman with model: %s\n", filepath.Base(modelPath))
+
+	// 🧠 Wingman prompt
+	prompt := `
 You are an installation step extractor.
 Analyze the following README and output ONLY the shell commands to install the project.
 List each command on its own line. No explanations, no comments.
 ---
 ` + string(content)
 
-    // 🚀 Wingman CLI çağrısı
-    cmd := exec.Command("wingman", "ask", prompt)
-    output, err := cmd.CombinedOutput()
-    if err != nil {
-        fmt.Printf("⚠️ Wingman failed: %v\nOutput: %s\n", err, string(output))
-        fmt.Println("↪ fallback to regex parser.")
-        return parseReadmeRegex(string(content))
-    }
-
-    raw := strings.TrimSpace(string(output))
-    cmds := strings.Split(raw, "\n")
-
-    if len(cmds) == 0 || raw == "" {
-        fmt.Println("[hyprrelease] AI found no install commands, fallback to regex parser.")
-        return parseReadmeRegex(string(content))
-    }
-
-    // 📋 Komutları yazdır
-    fmt.Println("[AI extracted install steps]:")
-    for i, c := range cmds {
-        fmt.Printf("%d. %s\n", i+1, strings.TrimSpace(c))
-    }
-
-    // ☑️ Kullanıcı onayı
-    fmt.Print("Proceed with installation? [Y/n]: ")
-    reader := bufio.NewReader(os.Stdin)
-    resp, _ := reader.ReadString('\n')
-    resp = strings.TrimSpace(resp)
-    if strings.ToLower(resp) != "y" && resp != "" {
-        return fmt.Errorf("installation aborted by user")
-    }
-
-    // 🧱 Komutları sırayla çalıştır
-    for _, c := range cmds {
-        c = strings.TrimSpace(c)
-        if c == "" {
-            continue
-        }
-
-        lower := strings.ToLower(c)
-        if strings.HasPrefix(lower, "sudo ") ||
-            strings.Contains(lower, "rm ") ||
-            strings.Contains(lower, ":(){ :|:& };:") ||
-            strings.Contains(lower, "mkfs") ||
-            strings.Contains(lower, "dd if=") {
-            fmt.Printf("⚠️ skipped dangerous command: %s\n", c)
-            continue
-        }
-
-        parts := strings.Fields(c)
-        if len(parts) == 0 {
-            continue
-        }
-
-        execCmd := exec.Command(parts[0], parts[1:]...)
-        execCmd.Stdout = os.Stdout
-        execCmd.Stderr = os.Stderr
-        fmt.Printf("→ executing: %s\n", c)
-
-        if err := execCmd.Run(); err != nil {
-            return fmt.Errorf("command failed (%s): %w", c, err)
-        }
-    }
-
-    return nil
+	// 🚀 Wingman CLI çağrısı
+	cmd := exec.Command("wingman", "ask", prompt)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(opts.out(), "⚠️ Wingman failed: %v\nOutput: %s\n", err, string(output))
+		fmt.Fprintln(opts.out(), "↪ fallback to regex parser.")
+		return parseReadmeRegex(string(content), opts)
+	}
+
+	raw := strings.TrimSpace(string(output))
+	cmds := strings.Split(raw, "\n")
+
+	if len(cmds) == 0 || raw == "" {
+		fmt.Fprintln(opts.out(), "[hyprrelease] AI found no install commands, fallback to regex parser.")
+		return parseReadmeRegex(string(content), opts)
+	}
+
+	// 📋 Komutları yazdır
+	fmt.Fprintln(opts.out(), "[AI extracted install steps]:")
+	for i, c := range cmds {
+		fmt.Fprintf(opts.out(), "%d. %s\n", i+1, strings.TrimSpace(c))
+	}
+
+	// ☑️ Kullanıcı onayı
+	ok, err := opts.prompter().Confirm(PromptReadmeProceed, "Proceed with installation?", true)
+	if err != nil {
+		return fmt.Errorf("confirmation failed: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("installation aborted by user")
+	}
+
+	// 🧱 Komutları sırayla çalıştır
+	for _, c := range cmds {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+
+		lower := strings.ToLower(c)
+		if strings.HasPrefix(lower, "sudo ") ||
+			strings.Contains(lower, "rm ") ||
+			strings.Contains(lower, ":(){ :|:& };:") ||
+			strings.Contains(lower, "mkfs") ||
+			strings.Contains(lower, "dd if=") {
+			fmt.Fprintf(opts.out(), "⚠️ skipped dangerous command: %s\n", c)
+			continue
+		}
+
+		parts := strings.Fields(c)
+		if len(parts) == 0 {
+			continue
+		}
+
+		execCmd := exec.Command(parts[0], parts[1:]...)
+		execCmd.Stdout = opts.out()
+		execCmd.Stderr = os.Stderr
+		fmt.Fprintf(opts.out(), "→ executing: %s\n", c)
+
+		if err := execCmd.Run(); err != nil {
+			return fmt.Errorf("command failed (%s): %w", c, err)
+		}
+	}
+
+	return nil
 }
 
 // Basit fallback regex parser
-func parseReadmeRegex(content string) error {
-    lines := strings.Split(content, "\n")
-    for _, line := range lines {
-        trimmed := strings.TrimSpace(line)
-        if strings.HasPrefix(trimmed, "git clone") ||
-            strings.Contains(trimmed, "github.com/hyprcommunity/hypr-release/install") ||
-            strings.Contains(trimmed, "make install") {
-            fmt.Println("→ executing (regex):", trimmed)
-            parts := strings.Fields(trimmed)
-            if len(parts) == 0 {
-                continue
-            }
-            cmd := exec.Command(parts[0], parts[1:]...)
-            cmd.Stdout = os.Stdout
-            cmd.Stderr = os.Stderr
-            if err := cmd.Run(); err != nil {
-                return fmt.Errorf("fallback command failed (%s): %w", trimmed, err)
-            }
-        }
-    }
-    return nil
+func parseReadmeRegex(content string, opts Options) error {
+	lines := strings.Split(content, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "git clone") ||
+			strings.Contains(trimmed, "github.com/hyprcommunity/hypr-release/install") ||
+			strings.Contains(trimmed, "make install") {
+			if opts.dryRun() {
+				opts.Plan.AddCommand(plan.OriginRegex, "", trimmed)
+				continue
+			}
+			fmt.Fprintln(opts.out(), "→ executing (regex):", trimmed)
+			parts := strings.Fields(trimmed)
+			if len(parts) == 0 {
+				continue
+			}
+			cmd := exec.Command(parts[0], parts[1:]...)
+			cmd.Stdout = opts.out()
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("fallback command failed (%s): %w", trimmed, err)
+			}
+		}
+	}
+	return nil
 }
 
 // ------------------------------------------------------------
 // AI tabanlı güvenli dosya seçimi
-func aiSafeFileInstall(repoPath string) error {
-    // 🔍 Model dizini taraması (sadece bilgilendirme amaçlı)
-    files, err := os.ReadDir(SystemModelDir)
-    if err != nil {
-        return fmt.Errorf("failed to read model directory: %v", err)
-    }
-
-    var modelPath string
-    for _, f := range files {
-        if strings.HasSuffix(f.Name(), ".gguf") {
-            modelPath = filepath.Join(SystemModelDir, f.Name())
-            break
-        }
-    }
-    if modelPath == "" {
-        fmt.Println("⚠️ no .gguf model found, continuing with Wingman LLM backend.")
-    } else {
-        fmt.Printf("[hyprrelease] using AI model (gguf detected): %s\n", filepath.Base(modelPath))
-    }
-
-    // 🔧 Dosya ağacını çıkar
-    var structure []string
-    filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
-        if err == nil {
-            rel, _ := filepath.Rel(repoPath, path)
-            if rel != "." {
-                structure = append(structure, rel)
-            }
-        }
-        return nil
-    })
-
-    // 🧠 LLM prompt
-    prompt := `
+func aiSafeFileInstall(repoPath string, opts Options) error {
+	// 🔍 Model dizini taraması (sadece bilgilendirme amaçlı)
+	files, err := os.ReadDir(SystemModelDir)
+	if err != nil {
+		return fmt.Errorf("failed to read model directory: %v", err)
+	}
+
+	var modelPath string
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".gguf") {
+			modelPath = filepath.Join(SystemModelDir, f.Name())
+			break
+		}
+	}
+	if modelPath == "" {
+		fmt.Fprintln(opts.out(), "⚠️ no .gguf model found, continuing with Wingman LLM backend.")
+	} else {
+		fmt.Fprintf(opts.out(), "[hyprrelease] using AI model (gguf detected): %s\n", filepath.Base(modelPath))
+	}
+
+	// 🔧 Dosya ağacını çıkar
+	var structure []string
+	filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err == nil {
+			rel, _ := filepath.Rel(repoPath, path)
+			if rel != "." {
+				structure = append(structure, rel)
+			}
+		}
+		return nil
+	})
+
+	// 🧠 LLM prompt
+	prompt := `
 You are a configuration installer AI.
 From this file tree, select ONLY configuration and script files safe to copy into ~/.config/hypr/.
 Prefer .conf, .ini, .json, .lua, .sh, .desktop files.
@@ -286,117 +430,180 @@ Return one relative path per line, no comments, no explanations.
 ---
 ` + strings.Join(structure, "\n")
 
-    // 🚀 Wingman CLI çağrısı
-    cmd := exec.Command("wingman", "ask", prompt)
-    output, err := cmd.CombinedOutput()
-    if err != nil {
-        return fmt.Errorf("wingman failed: %v\nOutput: %s", err, string(output))
-    }
-
-    raw := strings.TrimSpace(string(output))
-    filesList := strings.Split(raw, "\n")
-    if len(filesList) == 0 || raw == "" {
-        return fmt.Errorf("AI returned no file list")
-    }
-
-    fmt.Println("[AI selected safe files]:")
-    for _, f := range filesList {
-        fmt.Println(" →", f)
-    }
-
-    // ☑️ Kullanıcı onayı
-    fmt.Print("Proceed with AI-selected file copy? [Y/n]: ")
-    var resp string
-    fmt.Scanln(&resp)
-    if strings.ToLower(strings.TrimSpace(resp)) != "y" && resp != "" {
-        return fmt.Errorf("user aborted installation")
-    }
-
-    // 🎯 Hedef dizin
-    home, err := os.UserHomeDir()
-    if err != nil {
-        return fmt.Errorf("cannot resolve home directory: %v", err)
-    }
-    target := filepath.Join(home, ".config", "hypr")
-
-    // 📁 Dosyaları güvenli şekilde kopyala
-    for _, rel := range filesList {
-        rel = strings.TrimSpace(rel)
-        if rel == "" {
-            continue
-        }
-
-        src := filepath.Join(repoPath, rel)
-        dest := filepath.Join(target, rel)
-
-        info, err := os.Stat(src)
-        if err != nil || info.IsDir() {
-            fmt.Printf("⚠️ skipping invalid: %s\n", rel)
-            continue
-        }
-
-        if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
-            fmt.Printf("⚠️ failed to create directory for %s: %v\n", rel, err)
-            continue
-        }
-
-        in, err := os.Open(src)
-        if err != nil {
-            fmt.Printf("⚠️ failed to open source %s: %v\n", rel, err)
-            continue
-        }
-
-        out, err := os.Create(dest)
-        if err != nil {
-            in.Close()
-            fmt.Printf("⚠️ failed to create destination %s: %v\n", rel, err)
-            continue
-        }
-
-        if _, err := io.Copy(out, in); err != nil {
-            fmt.Printf("⚠️ copy error for %s: %v\n", rel, err)
-        } else {
-            fmt.Println("→ copied:", rel)
-        }
-
-        in.Close()
-        out.Close()
-    }
-
-    fmt.Println("✅ AI-selected configuration files successfully copied.")
-    return nil
+	// 🚀 Wingman CLI çağrısı
+	cmd := exec.Command("wingman", "ask", prompt)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("wingman failed: %v\nOutput: %s", err, string(output))
+	}
+
+	raw := strings.TrimSpace(string(output))
+	filesList := strings.Split(raw, "\n")
+	if len(filesList) == 0 || raw == "" {
+		return fmt.Errorf("AI returned no file list")
+	}
+
+	fmt.Fprintln(opts.out(), "[AI selected safe files]:")
+	for _, f := range filesList {
+		fmt.Fprintln(opts.out(), " →", f)
+	}
+
+	// ☑️ Kullanıcı onayı
+	if !opts.dryRun() {
+		ok, err := opts.prompter().Confirm(PromptAICopyProceed, "Proceed with AI-selected file copy?", true)
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("user aborted installation")
+		}
+	}
+
+	// 🎯 Hedef dizin
+	target := paths.HyprConfigDir()
+
+	// 📁 Dosyaları önce transaction'a al, sonra hepsini birden uygula
+	tx, err := beginInstall(repoPath, opts)
+	if err != nil {
+		return err
+	}
+	for _, rel := range filesList {
+		rel = strings.TrimSpace(rel)
+		if rel == "" {
+			continue
+		}
+
+		src := filepath.Join(repoPath, rel)
+		dest, ok := targetPath(target, rel)
+		if !ok {
+			fmt.Fprintf(opts.out(), "⚠️ skipping path outside %s: %s\n", target, rel)
+			continue
+		}
+
+		info, err := os.Stat(src)
+		if err != nil || info.IsDir() {
+			fmt.Fprintf(opts.out(), "⚠️ skipping invalid: %s\n", rel)
+			continue
+		}
+
+		if err := tx.Stage(src, dest); err != nil {
+			tx.Discard()
+			return fmt.Errorf("failed to stage %s: %v", rel, err)
+		}
+		fmt.Fprintln(opts.out(), "→ staged:", rel)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if !opts.dryRun() {
+		fmt.Fprintln(opts.out(), "✅ AI-selected configuration files successfully copied.")
+	}
+	return nil
 }
 
 // ------------------------------------------------------------
 // Klasik kopyalama fallback
-func defaultCopy(repoPath string) error {
-	home, _ := os.UserHomeDir()
-	target := filepath.Join(home, ".config", "hypr")
-	fmt.Println("[hyprrelease] default safe filter copy")
+func defaultCopy(repoPath string, opts Options) error {
+	target := paths.HyprConfigDir()
+	fmt.Fprintln(opts.out(), "[hyprrelease] default safe filter copy")
 
-	return filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+	tx, err := beginInstall(repoPath, opts)
+	if err != nil {
+		return err
+	}
+	exts := []string{".conf", ".ini", ".json", ".sh", ".png"}
+	err = filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
 			return nil
 		}
-		exts := []string{".conf", ".ini", ".json", ".sh", ".png"}
 		for _, e := range exts {
 			if strings.HasSuffix(path, e) {
 				rel, _ := filepath.Rel(repoPath, path)
-				dest := filepath.Join(target, rel)
-				os.MkdirAll(filepath.Dir(dest), 0755)
-				src, _ := os.Open(path)
-				defer src.Close()
-				dst, _ := os.Create(dest)
-				defer dst.Close()
-				io.Copy(dst, src)
-				fmt.Println("→ copied:", rel)
+				if err := tx.Stage(path, filepath.Join(target, rel)); err != nil {
+					return fmt.Errorf("failed to stage %s: %v", rel, err)
+				}
+				fmt.Fprintln(opts.out(), "→ staged:", rel)
+				break
 			}
 		}
 		return nil
 	})
+	if err != nil {
+		tx.Discard()
+		return err
+	}
+	return tx.Commit()
+}
+
+// runTracked : runs an installer that cannot be staged (install script or
+// README commands) inside a transaction that snapshots the Hyprland config
+// directory, so a failed run is undone and a successful one can still be
+// rolled back later. Changes outside that directory are not tracked.
+func runTracked(repoPath string, opts Options, install func() error) error {
+	target := paths.HyprConfigDir()
+	if opts.dryRun() {
+		opts.Plan.Note("files under %s are snapshotted before running and restored if it fails", target)
+		opts.Plan.AddMetadata(manifestPath(opts.dotfile(repoPath)), manifestKeys)
+		return install()
+	}
+	tx, err := beginInstall(repoPath, opts)
+	if err != nil {
+		return err
+	}
+	if err := tx.Snapshot(target); err != nil {
+		tx.Discard()
+		return err
+	}
+	if err := install(); err != nil {
+		// yeni oluşan dosyalar da geri alınsın diye önce kaydet
+		if capErr := tx.Capture(target); capErr != nil {
+			fmt.Fprintln(opts.out(), "⚠️", capErr)
+		}
+		if rbErr := tx.Rollback(); rbErr != nil {
+			fmt.Fprintln(opts.out(), "⚠️ rollback failed:", rbErr)
+		}
+		return err
+	}
+	return tx.Capture(target)
+}
+
+// beginInstall : transaction for installing repoPath, recorded under the
+// registry entry name (see Options.Dotfile). Its HEAD is the installed
+// commit; submodule pins are recorded alongside it.
+//
+// In dry-run mode the returned transaction only records into opts.Plan.
+func beginInstall(repoPath string, opts Options) (*Transaction, error) {
+	if opts.dryRun() {
+		return &Transaction{Dotfile: opts.dotfile(repoPath), State: TxStaged, plan: opts.Plan, w: opts.Out}, nil
+	}
+	tx, err := BeginTransaction(opts.dotfile(repoPath))
+	if err != nil {
+		return nil, err
+	}
+	tx.w = opts.Out
+	if head, err := mirror.Head(repoPath); err == nil {
+		tx.Revision = head
+	}
+	if tx.Submodules, err = mirror.Submodules(repoPath); err != nil {
+		fmt.Fprintln(opts.out(), "⚠️", err)
+	}
+	return tx, nil
+}
+
+// targetPath : joins rel onto target, refusing paths that escape it
+func targetPath(target, rel string) (string, bool) {
+	dest := filepath.Join(target, rel)
+	if dest != target && !strings.HasPrefix(dest, target+string(os.PathSeparator)) {
+		return "", false
+	}
+	return dest, true
 }
 
 // ------------------------------------------------------------
@@ -411,16 +618,3 @@ func findReadme(repoPath string) string {
 	}
 	return ""
 }
-
-func runCmd(cmdline string) {
-	parts := strings.Fields(cmdline)
-	if len(parts) == 0 {
-		return
-	}
-	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		fmt.Println("⚠️ command failed:", err)
-	}
-}
@@ -0,0 +1,337 @@
+package updateing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/mirror"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+// Manifest : files an install placed on disk, used for a clean uninstall.
+// Stored as $XDG_STATE_HOME/hypr-release/manifests/<dotfile>.json.
+type Manifest struct {
+	Dotfile     string         `json:"dotfile"`
+	Commit      string         `json:"commit,omitempty"`
+	Transaction string         `json:"transaction"`
+	InstalledAt time.Time      `json:"installed_at"`
+	Files       []ManifestFile `json:"files"`
+	// Dirs : directories created by installs, parents first
+	Dirs []string `json:"dirs,omitempty"`
+	// Submodules : submodules of the installed commit and their pins
+	Submodules []mirror.Submodule `json:"submodules,omitempty"`
+}
+
+// ManifestFile : one installed file and what it replaced
+type ManifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	// Original : backup of the file this one replaced (empty if it was new)
+	Original string `json:"original,omitempty"`
+	// OriginalLink : target of the symlink this file replaced
+	OriginalLink string `json:"original_link,omitempty"`
+}
+
+// manifestKeys : top-level manifest fields, listed in dry-run plans
+var manifestKeys = []string{"dotfile", "commit", "transaction", "installed_at", "files", "dirs", "submodules"}
+
+func manifestsDir() string {
+	return filepath.Join(paths.StateDir(), "manifests")
+}
+
+func manifestPath(dotfile string) string {
+	return filepath.Join(manifestsDir(), sanitizeID(dotfile)+".json")
+}
+
+// LoadManifest : manifest of an installed dotfile
+func LoadManifest(dotfile string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath(dotfile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no install manifest for %s", dotfile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("corrupt manifest for %s: %v", dotfile, err)
+	}
+	return &m, nil
+}
+
+// ListManifests : manifests of every installed dotfile, sorted by name
+func ListManifests() ([]*Manifest, error) {
+	files, err := os.ReadDir(manifestsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Manifest
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(manifestsDir(), f.Name()))
+		if err != nil {
+			continue
+		}
+		var m Manifest
+		if json.Unmarshal(data, &m) == nil {
+			list = append(list, &m)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Dotfile < list[j].Dotfile })
+	return list, nil
+}
+
+func (m *Manifest) save() error {
+	if err := os.MkdirAll(manifestsDir(), 0755); err != nil {
+		return fmt.Errorf("cannot create manifest directory: %v", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := manifestPath(m.Dotfile)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("cannot write manifest: %v", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// writeManifest : records the files a committed transaction owns. Files the
+// transaction only snapshotted and left unchanged are not owned. When the
+// dotfile was installed before, the earlier manifest's originals are kept,
+// so uninstall restores what was there before the first install.
+func (t *Transaction) writeManifest() error {
+	m := &Manifest{
+		Dotfile:     t.Dotfile,
+		Commit:      t.Revision,
+		Transaction: t.ID,
+		InstalledAt: time.Now(),
+		Dirs:        t.Dirs,
+		Submodules:  t.Submodules,
+	}
+	for _, e := range t.Entries {
+		f, owned, err := e.manifestFile()
+		if err != nil {
+			return err
+		}
+		if owned {
+			m.Files = append(m.Files, f)
+		}
+	}
+
+	if prev, err := LoadManifest(t.Dotfile); err == nil {
+		// geri alma için önceki manifesti sakla
+		data, _ := json.MarshalIndent(prev, "", "  ")
+		if err := os.WriteFile(filepath.Join(t.dir, "previous-manifest.json"), data, 0644); err != nil {
+			return err
+		}
+		m.merge(prev)
+	}
+	return m.save()
+}
+
+// manifestFile : manifest record for an applied entry; owned is false when
+// the destination is gone or identical to what was there before
+func (e TxEntry) manifestFile() (ManifestFile, bool, error) {
+	f := ManifestFile{Path: e.Dest, Original: e.Backup, OriginalLink: e.Link}
+	if !e.Applied {
+		return f, false, nil
+	}
+	if e.Link != "" {
+		if target, err := os.Readlink(e.Dest); err == nil && target == e.Link {
+			return f, false, nil
+		}
+	}
+	info, err := os.Lstat(e.Dest)
+	if os.IsNotExist(err) || (err == nil && !info.Mode().IsRegular()) {
+		return f, false, nil
+	}
+	if err != nil {
+		return f, false, err
+	}
+	sum, err := calcLocalChecksum(e.Dest)
+	if err != nil {
+		return f, false, err
+	}
+	f.SHA256 = sum
+	if e.Backup != "" {
+		if old, err := calcLocalChecksum(e.Backup); err == nil && old == sum {
+			return f, false, nil
+		}
+	}
+	return f, true, nil
+}
+
+// merge : carries over originals and still-owned files from an earlier install
+func (m *Manifest) merge(prev *Manifest) {
+	index := make(map[string]int, len(m.Files))
+	for i, f := range m.Files {
+		index[f.Path] = i
+	}
+	for _, old := range prev.Files {
+		if i, ok := index[old.Path]; ok {
+			m.Files[i].Original = old.Original
+			m.Files[i].OriginalLink = old.OriginalLink
+			continue
+		}
+		// önceki kurulumdan kalan ve değişmemiş dosyalar hâlâ bize ait
+		if sum, err := calcLocalChecksum(old.Path); err == nil && sum == old.SHA256 {
+			m.Files = append(m.Files, old)
+		}
+	}
+
+	seen := make(map[string]bool)
+	dirs := append(append([]string{}, prev.Dirs...), m.Dirs...)
+	m.Dirs = m.Dirs[:0]
+	for _, d := range dirs {
+		if !seen[d] {
+			seen[d] = true
+			m.Dirs = append(m.Dirs, d)
+		}
+	}
+}
+
+// restorePreviousManifest : undoes writeManifest when its transaction is rolled back
+func (t *Transaction) restorePreviousManifest() error {
+	m, err := LoadManifest(t.Dotfile)
+	if err != nil || m.Transaction != t.ID {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(t.dir, "previous-manifest.json"))
+	if os.IsNotExist(err) {
+		return os.Remove(manifestPath(t.Dotfile))
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(t.Dotfile), data, 0644)
+}
+
+// findManifest : manifest of dotfile, matched like registry names
+// (case-insensitive); the registry spelling is tried first, then every
+// manifest, so entries since removed from the registry are still found
+func findManifest(dotfile string) (*Manifest, error) {
+	if d := summaryofversion.GetDotfileByName(dotfile); d != nil {
+		if m, err := LoadManifest(d.Name); err == nil {
+			return m, nil
+		}
+	}
+	if m, err := LoadManifest(dotfile); err == nil {
+		return m, nil
+	}
+	all, err := ListManifests()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range all {
+		if strings.EqualFold(m.Dotfile, dotfile) {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no install manifest for %s", dotfile)
+}
+
+// Uninstall : removes the files a dotfile install owns and restores the
+// files they replaced. Files changed since the install are left alone
+// unless force is set. dotfile is matched case-insensitively. With
+// opts.Plan nothing changes; the deletions and restores are planned.
+func Uninstall(dotfile string, force bool, opts Options) error {
+	p := opts.Plan
+	m, err := findManifest(dotfile)
+	if err != nil {
+		return err
+	}
+
+	owners := make(map[string]string)
+	if all, err := ListManifests(); err == nil {
+		for _, other := range all {
+			if other.Dotfile == m.Dotfile {
+				continue
+			}
+			for _, f := range other.Files {
+				owners[f.Path] = other.Dotfile
+			}
+		}
+	}
+
+	var kept []ManifestFile
+	removed, restored := 0, 0
+	for i := len(m.Files) - 1; i >= 0; i-- {
+		f := m.Files[i]
+		if owner, ok := owners[f.Path]; ok {
+			fmt.Fprintf(opts.out(), "⚠️ keeping %s: now owned by %s\n", f.Path, owner)
+			continue
+		}
+		sum, err := calcLocalChecksum(f.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot read %s: %v", f.Path, err)
+		}
+		if err == nil && sum != f.SHA256 && !force {
+			fmt.Fprintf(opts.out(), "⚠️ keeping %s: modified since install (use force to remove)\n", f.Path)
+			kept = append(kept, f)
+			continue
+		}
+
+		entry := TxEntry{Dest: f.Path, Backup: f.Original, Link: f.OriginalLink, Existed: f.Original != "" || f.OriginalLink != ""}
+		if p != nil {
+			switch {
+			case f.Original != "":
+				p.AddFile(f.Path, f.Original)
+			case f.OriginalLink != "":
+				p.AddFile(f.Path, "symlink to "+f.OriginalLink)
+			default:
+				p.AddDelete(f.Path)
+			}
+			continue
+		}
+		if err := entry.restore(); err != nil {
+			return fmt.Errorf("failed to restore %s: %v", f.Path, err)
+		}
+		if entry.Existed {
+			restored++
+			fmt.Fprintln(opts.out(), "→ restored:", f.Path)
+		} else {
+			removed++
+			fmt.Fprintln(opts.out(), "→ removed:", f.Path)
+		}
+	}
+	if p != nil {
+		if len(m.Dirs) > 0 {
+			p.Note("directories created by the install are removed when empty: %s", strings.Join(m.Dirs, ", "))
+		}
+		if len(kept) > 0 {
+			p.AddMetadata(manifestPath(m.Dotfile), manifestKeys)
+			p.Note("%d modified files are kept (use force to remove them)", len(kept))
+		} else {
+			p.AddDelete(manifestPath(m.Dotfile))
+		}
+		return nil
+	}
+	for i := len(m.Dirs) - 1; i >= 0; i-- {
+		os.Remove(m.Dirs[i])
+	}
+
+	if len(kept) > 0 {
+		m.Files = kept
+		if err := m.save(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%s partially uninstalled: %d modified files kept", m.Dotfile, len(kept))
+	}
+	if err := os.Remove(manifestPath(m.Dotfile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	fmt.Fprintf(opts.out(), "[hyprrelease] %s uninstalled (%d removed, %d restored)\n", m.Dotfile, removed, restored)
+	return nil
+}
@@ -0,0 +1,126 @@
+package updateing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
+	"github.com/hyprcommunity/hypr-release/api/releases/prompt"
+)
+
+// Prompt keys : stable identifiers of every question asked by this package.
+// Answers files (prompt.LoadAnswers) use these as keys.
+const (
+	PromptInstallRef      = "install.ref"
+	PromptReadmeProceed   = "install.readme.proceed"
+	PromptAICopyProceed   = "install.ai_copy.proceed"
+	PromptUpdateReinstall = "update.reinstall"
+	PromptModelSelect     = "models.select"
+	PromptRedirectFollow  = "registry.redirect.follow"
+	PromptMetadataMigrate = "metadata.migrate"
+)
+
+// Options : controls how install and update operations interact with the user
+type Options struct {
+	// Prompter answers confirmations and choices; nil means the terminal.
+	Prompter prompt.Prompter
+	// Plan, when set, turns the operation into a dry run: files, commands
+	// and metadata it would write are recorded here instead.
+	Plan *plan.Plan
+	// Installer : repository-relative installer script to try before the
+	// default ones (set from the registry entry by InstallFromRegistry)
+	Installer string
+	// Dotfile : registry entry name the install is recorded under
+	// (manifest, transactions); set by InstallFromRegistry. Empty means
+	// the repository directory name.
+	Dotfile string
+	// Offline : use the repository cache as last fetched, without network
+	Offline bool
+	// Ref : branch, tag or commit to install instead of asking; empty
+	// means the entry's branch
+	Ref string
+	// At : install the last commit of the ref made before this time
+	At time.Time
+	// Out : receives progress output; nil means stdout. Plans printed as
+	// JSON send it elsewhere so stdout carries only the plan.
+	Out io.Writer
+}
+
+// ParseAt : parses an --at value: a date (2024-05-01), a date and time
+// (2024-05-01 15:04) or RFC 3339. Dates without a zone are local time.
+func ParseAt(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			if layout == "2006-01-02" {
+				// whole day: commits made on that date count
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, \"YYYY-MM-DD HH:MM\" or RFC 3339", s)
+}
+
+// dotfile : name installs of repoPath are recorded under
+func (o Options) dotfile(repoPath string) string {
+	if o.Dotfile != "" {
+		return o.Dotfile
+	}
+	return filepath.Base(repoPath)
+}
+
+func (o Options) out() io.Writer {
+	if o.Out == nil {
+		return os.Stdout
+	}
+	return o.Out
+}
+
+func (o Options) dryRun() bool {
+	return o.Plan != nil
+}
+
+// confirm : asks a yes/no question. Dry runs ask nothing: the default
+// answer is used and the question noted in the plan.
+func (o Options) confirm(key, question string, def bool) (bool, error) {
+	if o.dryRun() {
+		answer := "no"
+		if def {
+			answer = "yes"
+		}
+		o.Plan.Note("%q (%s) would be asked; planned as %s", question, key, answer)
+		return def, nil
+	}
+	return o.prompter().Confirm(key, question, def)
+}
+
+// input : asks for free text; dry runs use def like confirm
+func (o Options) input(key, question, def string) (string, error) {
+	if o.dryRun() {
+		o.Plan.Note("%q (%s) would be asked; planned with %q", question, key, def)
+		return def, nil
+	}
+	return o.prompter().Input(key, question, def)
+}
+
+func (o Options) prompter() prompt.Prompter {
+	if o.Prompter == nil {
+		return prompt.Stdio()
+	}
+	return o.Prompter
+}
+
+// isPromptError : true when a question could not be answered at all, as
+// opposed to the user declining it. Such errors abort the whole operation
+// instead of falling through to the next install strategy.
+func isPromptError(err error) bool {
+	var answerErr *prompt.AnswerError
+	return errors.As(err, &answerErr) || errors.Is(err, prompt.ErrCanceled)
+}
@@ -0,0 +1,143 @@
+package updateing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+// resolveEntry : looks name up in the registry, warns about deprecated
+// entries and follows redirects (summaryofversion.Resolve). moved_to is
+// always followed; following replaced_by is offered to the user. Returns
+// the entry to work with and, when it differs from the requested one, the
+// entry that was asked for.
+func resolveEntry(name string, opts Options) (selected, requested *summaryofversion.Dotfile, err error) {
+	orig := summaryofversion.GetDotfileByName(name)
+	if orig == nil {
+		return nil, nil, summaryofversion.NotFoundError(name)
+	}
+
+	warned := make(map[string]bool)
+	warnDeprecated := func(d summaryofversion.Dotfile) {
+		if d.Deprecated && !warned[d.Name] {
+			warned[d.Name] = true
+			fmt.Fprintf(opts.out(), "⚠️ %s is deprecated\n", d.Name)
+		}
+	}
+	d, redirects, err := summaryofversion.Resolve(name, func(from, to summaryofversion.Dotfile) (bool, error) {
+		warnDeprecated(from)
+		follow, err := opts.confirm(PromptRedirectFollow,
+			fmt.Sprintf("%s is replaced by %s. Use %s instead?", from.Name, to.Name, to.Name), true)
+		if err != nil {
+			return false, fmt.Errorf("redirect confirmation failed: %w", err)
+		}
+		if !follow {
+			fmt.Fprintf(opts.out(), "[hyprrelease] staying on %s\n", from.Name)
+			return false, nil
+		}
+		fmt.Fprintf(opts.out(), "[hyprrelease] ↪ following redirect: %s → %s\n", from.Name, to.Name)
+		if opts.dryRun() {
+			opts.Plan.Note("%s is replaced by %s; the plan uses %s", from.Name, to.Name, to.Name)
+		}
+		return true, nil
+	})
+	if errors.Is(err, summaryofversion.ErrNoSuccessor) {
+		fmt.Fprintln(opts.out(), "⚠️", err)
+		err = nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range redirects {
+		if r.Kind == summaryofversion.RedirectMoved {
+			fmt.Fprintf(opts.out(), "[hyprrelease] ↪ %s\n", r)
+		}
+	}
+	warnDeprecated(*d)
+
+	if strings.EqualFold(d.Name, orig.Name) && d.Repo == orig.Repo {
+		return d, nil, nil
+	}
+	requested = new(summaryofversion.Dotfile)
+	*requested = *orig
+	return d, requested, nil
+}
+
+// metadataKeys : keys rewritten when an install moves to another entry
+var metadataKeys = []string{
+	"HYPRLAND_DOTFILES_NAME",
+	"HYPRLAND_DOTFILES_AUTHOR",
+	"HYPRLAND_DOTFILES_BRANCH",
+	"HYPRLAND_REMOTE_URL",
+}
+
+// offerMetadataMigration : if the hyprland-release metadata still points to
+// the old entry, offers to rewrite its name and remote URL (and author and
+// branch when the entry was replaced) to the new one. Other keys are kept.
+// Returns false when the user wants the metadata to stay on the old entry.
+func offerMetadataMigration(from, to summaryofversion.Dotfile, opts Options) (bool, error) {
+	path, values := readMetaFile()
+	if path == "" {
+		return true, nil
+	}
+	if !strings.EqualFold(values["HYPRLAND_DOTFILES_NAME"], from.Name) && values["HYPRLAND_REMOTE_URL"] != from.Repo {
+		return true, nil
+	}
+
+	updates := map[string]string{"HYPRLAND_REMOTE_URL": to.Repo}
+	if !strings.EqualFold(from.Name, to.Name) {
+		updates["HYPRLAND_DOTFILES_NAME"] = to.Name
+		updates["HYPRLAND_DOTFILES_AUTHOR"] = to.Author
+		updates["HYPRLAND_DOTFILES_BRANCH"] = to.Branch
+	}
+
+	migrate, err := opts.confirm(PromptMetadataMigrate,
+		fmt.Sprintf("Metadata in %s refers to %s. Migrate it to %s?", path, from.Name, to.Name), true)
+	if err != nil {
+		return false, fmt.Errorf("migration confirmation failed: %w", err)
+	}
+	if !migrate {
+		fmt.Fprintf(opts.out(), "[hyprrelease] metadata left on %s\n", from.Name)
+		return false, nil
+	}
+
+	var keys []string
+	for _, k := range metadataKeys {
+		if _, ok := updates[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	if opts.dryRun() {
+		opts.Plan.AddMetadata(path, keys)
+		return true, nil
+	}
+
+	if err := updateMetaKeys(path, updates); err != nil {
+		return false, fmt.Errorf("metadata migration failed: %v", err)
+	}
+	fmt.Fprintf(opts.out(), "✅ metadata migrated: %s → %s (%s)\n", from.Name, to.Name, path)
+	return true, nil
+}
+
+// readMetaFile : the metadata file in use (user first, then system) and its values
+func readMetaFile() (string, map[string]string) {
+	for _, path := range []string{userMetaPath(), systemMetaPath} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		values := make(map[string]string)
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok || strings.HasPrefix(key, "#") {
+				continue
+			}
+			values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+		return path, values
+	}
+	return "", nil
+}
@@ -0,0 +1,48 @@
+package updateing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/prompt"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+func TestResolveEntry(t *testing.T) {
+	isolate(t)
+	index := ""
+	for name, extra := range map[string]string{
+		"Old":  "replaced_by = \"New\"\ndeprecated = true\n",
+		"New":  "",
+		"Ping": "replaced_by = \"Pong\"\n",
+		"Pong": "replaced_by = \"Ping\"\n",
+	} {
+		index += "[[dotfile]]\nname = \"" + name + "\"\nrepo = \"https://git.example.com/" + strings.ToLower(name) + ".git\"\n" + extra
+	}
+	path := filepath.Join(paths.ConfigDir(), "registry.d", "user.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	summaryofversion.Reload()
+	t.Cleanup(summaryofversion.Reload)
+
+	var out strings.Builder
+	opts := Options{Prompter: prompt.AssumeYes{}, Out: &out}
+	selected, requested, err := resolveEntry("old", opts)
+	if err != nil || selected.Name != "New" || requested == nil || requested.Name != "Old" {
+		t.Fatalf("resolveEntry(old) = %+v, %+v, %v", selected, requested, err)
+	}
+	if !strings.Contains(out.String(), "Old is deprecated") || !strings.Contains(out.String(), "following redirect: Old → New") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	if _, _, err := resolveEntry("Ping", opts); err == nil || !strings.Contains(err.Error(), "loop") {
+		t.Errorf("resolveEntry(Ping) = %v, want a loop error", err)
+	}
+}
@@ -0,0 +1,536 @@
+package updateing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/mirror"
+	"github.com/hyprcommunity/hypr-release/api/releases/paths"
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
+)
+
+// Transaction states
+const (
+	TxStaged     = "staged"
+	TxCommitted  = "committed"
+	TxRolledBack = "rolled-back"
+)
+
+// Transaction : a set of file changes applied together and undoable later.
+// Files are staged into the transaction directory first; Commit snapshots
+// every file it is about to replace and then moves the staged files into
+// place. If any step fails, all changes made so far are undone.
+//
+// The journal lives in $XDG_STATE_HOME/hypr-release/transactions/<id>/ and
+// is saved before each destination is touched, so an interrupted commit
+// can still be rolled back.
+type Transaction struct {
+	ID        string    `json:"id"`
+	Dotfile   string    `json:"dotfile"`
+	Revision  string    `json:"revision,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	State     string    `json:"state"`
+	Entries   []TxEntry `json:"entries"`
+	// Dirs : directories created by the transaction, parents first
+	Dirs []string `json:"dirs,omitempty"`
+	// Submodules : submodule commits of the installed revision
+	Submodules []mirror.Submodule `json:"submodules,omitempty"`
+
+	dir   string
+	known map[string]bool
+	// plan : set for dry runs; Stage and Commit only record into it
+	plan *plan.Plan
+	// w : progress output; nil means stdout
+	w io.Writer
+}
+
+func (t *Transaction) out() io.Writer {
+	if t.w == nil {
+		return os.Stdout
+	}
+	return t.w
+}
+
+// TxEntry : a single destination path touched by a transaction
+type TxEntry struct {
+	Dest    string `json:"dest"`
+	Staged  string `json:"staged,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+	Link    string `json:"link,omitempty"`
+	Existed bool   `json:"existed"`
+	Applied bool   `json:"applied"`
+}
+
+func transactionsDir() string {
+	return filepath.Join(paths.StateDir(), "transactions")
+}
+
+// BeginTransaction : creates an empty transaction for dotfile
+func BeginTransaction(dotfile string) (*Transaction, error) {
+	base := time.Now().Format("20060102-150405") + "-" + sanitizeID(dotfile)
+	if err := os.MkdirAll(transactionsDir(), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create transaction directory: %v", err)
+	}
+
+	id := base
+	for n := 2; ; n++ {
+		err := os.Mkdir(filepath.Join(transactionsDir(), id), 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("cannot create transaction directory: %v", err)
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	t := &Transaction{
+		ID:        id,
+		Dotfile:   dotfile,
+		CreatedAt: time.Now(),
+		State:     TxStaged,
+		dir:       filepath.Join(transactionsDir(), id),
+	}
+	for _, sub := range []string{"staged", "backup"} {
+		if err := os.MkdirAll(filepath.Join(t.dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("cannot create transaction directory: %v", err)
+		}
+	}
+	return t, t.save()
+}
+
+// Stage : copies src into the transaction; it is written to dest on Commit
+func (t *Transaction) Stage(src, dest string) error {
+	if t.State != TxStaged {
+		return fmt.Errorf("transaction %s is %s", t.ID, t.State)
+	}
+	dest = filepath.Clean(dest)
+	if t.plan != nil {
+		t.plan.AddFile(dest, src)
+		return nil
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", src)
+	}
+
+	index := len(t.Entries)
+	for i, e := range t.Entries {
+		if e.Dest == dest {
+			index = i
+			break
+		}
+	}
+	staged := filepath.Join(t.dir, "staged", strconv.Itoa(index))
+	if err := copyFile(src, staged, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if index == len(t.Entries) {
+		t.Entries = append(t.Entries, TxEntry{Dest: dest, Staged: staged})
+	}
+	return nil
+}
+
+// Commit : snapshots replaced files and applies every staged file. On error
+// the transaction is rolled back before returning.
+func (t *Transaction) Commit() error {
+	if t.State != TxStaged {
+		return fmt.Errorf("transaction %s is %s", t.ID, t.State)
+	}
+	if t.plan != nil {
+		t.plan.AddMetadata(manifestPath(t.Dotfile), manifestKeys)
+		t.plan.Note("replaced files are backed up under %s", transactionsDir())
+		return nil
+	}
+	for i := range t.Entries {
+		if err := t.apply(i); err != nil {
+			if rbErr := t.undo(); rbErr != nil {
+				return fmt.Errorf("commit failed: %v (rollback failed: %v)", err, rbErr)
+			}
+			t.State = TxRolledBack
+			t.save()
+			return fmt.Errorf("commit failed, changes rolled back: %v", err)
+		}
+	}
+	t.State = TxCommitted
+	os.RemoveAll(filepath.Join(t.dir, "staged"))
+	if err := t.save(); err != nil {
+		return err
+	}
+	if err := t.writeManifest(); err != nil {
+		fmt.Fprintln(t.out(), "⚠️ failed to write install manifest:", err)
+	}
+	fmt.Fprintf(t.out(), "[hyprrelease] transaction %s committed (%d files)\n", t.ID, len(t.Entries))
+	return nil
+}
+
+func (t *Transaction) apply(i int) error {
+	e := &t.Entries[i]
+	if err := t.snapshot(i); err != nil {
+		return err
+	}
+	if err := t.mkdirAll(filepath.Dir(e.Dest)); err != nil {
+		return err
+	}
+
+	// journal first, so an interrupted commit can still be undone
+	e.Applied = true
+	if err := t.save(); err != nil {
+		e.Applied = false
+		return err
+	}
+
+	info, err := os.Stat(e.Staged)
+	if err != nil {
+		return err
+	}
+	if e.Link != "" {
+		if err := os.Remove(e.Dest); err != nil {
+			return err
+		}
+	}
+	return copyFile(e.Staged, e.Dest, info.Mode().Perm())
+}
+
+// snapshot : backs up whatever currently lives at entry i's destination
+func (t *Transaction) snapshot(i int) error {
+	e := &t.Entries[i]
+	info, err := os.Lstat(e.Dest)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	e.Existed = true
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		e.Link, err = os.Readlink(e.Dest)
+		return err
+	case info.IsDir():
+		return fmt.Errorf("%s is a directory", e.Dest)
+	}
+	e.Backup = filepath.Join(t.dir, "backup", strconv.Itoa(i))
+	if err := copyFile(e.Dest, e.Backup, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("cannot back up %s: %v", e.Dest, err)
+	}
+	return nil
+}
+
+// mkdirAll : like os.MkdirAll, but remembers the directories it created
+func (t *Transaction) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		t.Dirs = append(t.Dirs, missing[i])
+	}
+	return nil
+}
+
+// Snapshot : records every file under root before an installer that cannot
+// be staged (an install script or README commands) runs. Use Capture after
+// the installer succeeds, or Rollback if it fails.
+func (t *Transaction) Snapshot(root string) error {
+	t.known = make(map[string]bool)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		t.known[path] = true
+		if d.IsDir() || (!d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0) {
+			return nil
+		}
+		t.Entries = append(t.Entries, TxEntry{Dest: path, Applied: true})
+		return t.snapshot(len(t.Entries) - 1)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot snapshot %s: %v", root, err)
+	}
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		t.Dirs = append(t.Dirs, root)
+	}
+	return t.save()
+}
+
+// Capture : records files and directories created under root since
+// Snapshot and marks the transaction committed
+func (t *Transaction) Capture(root string) error {
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if t.known[path] {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root {
+				t.Dirs = append(t.Dirs, path)
+			}
+			return nil
+		}
+		t.Entries = append(t.Entries, TxEntry{Dest: path, Applied: true})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot scan %s: %v", root, err)
+	}
+	t.State = TxCommitted
+	os.RemoveAll(filepath.Join(t.dir, "staged"))
+	if err := t.save(); err != nil {
+		return err
+	}
+	if err := t.writeManifest(); err != nil {
+		fmt.Fprintln(t.out(), "⚠️ failed to write install manifest:", err)
+	}
+	return nil
+}
+
+// Rollback : restores every replaced file and removes every created one
+func (t *Transaction) Rollback() error {
+	if t.State == TxRolledBack {
+		return fmt.Errorf("transaction %s is already rolled back", t.ID)
+	}
+	if err := t.undo(); err != nil {
+		t.save()
+		return err
+	}
+	t.State = TxRolledBack
+	if err := t.save(); err != nil {
+		return err
+	}
+	if err := t.restorePreviousManifest(); err != nil {
+		fmt.Fprintln(t.out(), "⚠️ failed to restore install manifest:", err)
+	}
+	fmt.Fprintf(t.out(), "[hyprrelease] transaction %s rolled back\n", t.ID)
+	return nil
+}
+
+// undo : reverts applied entries in reverse order; keeps going on errors
+// so that as much as possible is restored
+func (t *Transaction) undo() error {
+	var failed []string
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		e := &t.Entries[i]
+		if !e.Applied {
+			continue
+		}
+		if err := e.restore(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", e.Dest, err))
+			continue
+		}
+		e.Applied = false
+	}
+	for i := len(t.Dirs) - 1; i >= 0; i-- {
+		// only empty directories go; anything else was not ours alone
+		os.Remove(t.Dirs[i])
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restore %d files:\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+func (e TxEntry) restore() error {
+	if err := os.Remove(e.Dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	switch {
+	case !e.Existed:
+		return nil
+	case e.Link != "":
+		return os.Symlink(e.Link, e.Dest)
+	}
+	info, err := os.Stat(e.Backup)
+	if err != nil {
+		return fmt.Errorf("backup missing: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Dest), 0755); err != nil {
+		return err
+	}
+	return copyFile(e.Backup, e.Dest, info.Mode().Perm())
+}
+
+// Discard : deletes a transaction that was never committed
+func (t *Transaction) Discard() error {
+	if t.State == TxCommitted {
+		return fmt.Errorf("transaction %s is committed; use Rollback", t.ID)
+	}
+	if t.plan != nil {
+		return nil
+	}
+	return os.RemoveAll(t.dir)
+}
+
+func (t *Transaction) save() error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(t.dir, "journal.json")
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("cannot write transaction journal: %v", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// LoadTransaction : reads a transaction journal by id
+func LoadTransaction(id string) (*Transaction, error) {
+	dir := filepath.Join(transactionsDir(), id)
+	data, err := os.ReadFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		return nil, fmt.Errorf("transaction not found: %s", id)
+	}
+	var t Transaction
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("corrupt transaction journal %s: %v", id, err)
+	}
+	t.dir = dir
+	return &t, nil
+}
+
+// ListTransactions : all recorded transactions, oldest first; unreadable
+// journals are reported to opts.Out and skipped
+func ListTransactions(opts Options) ([]*Transaction, error) {
+	dirs, err := os.ReadDir(transactionsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Transaction
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		t, err := LoadTransaction(d.Name())
+		if err != nil {
+			fmt.Fprintln(opts.out(), "⚠️", err)
+			continue
+		}
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+// Rollback : rolls back transaction id, or the latest committed one if id
+// is empty. Only committed transactions can be rolled back, and only when
+// no newer committed transaction touched the same files: their backups
+// would overwrite the newer install, so those have to be rolled back first.
+func Rollback(id string, opts Options) error {
+	list, err := ListTransactions(opts)
+	if err != nil {
+		return err
+	}
+	var t *Transaction
+	if id == "" {
+		for i := len(list) - 1; i >= 0; i-- {
+			if list[i].State == TxCommitted {
+				t = list[i]
+				break
+			}
+		}
+		if t == nil {
+			return fmt.Errorf("no committed transaction to roll back")
+		}
+	} else {
+		if t, err = LoadTransaction(id); err != nil {
+			return err
+		}
+		if t.State != TxCommitted {
+			return fmt.Errorf("transaction %s is %s; only committed transactions can be rolled back", t.ID, t.State)
+		}
+		if newer := t.overwrittenBy(list); len(newer) > 0 {
+			return fmt.Errorf("transaction %s touched files changed later by %s; roll back %s first", t.ID, strings.Join(newer, ", "), newer[len(newer)-1])
+		}
+	}
+	t.w = opts.Out
+	return t.Rollback()
+}
+
+// overwrittenBy : ids of the committed transactions in list, newer than t,
+// that share a destination with t; newest last
+func (t *Transaction) overwrittenBy(list []*Transaction) []string {
+	dests := make(map[string]bool, len(t.Entries))
+	for _, e := range t.Entries {
+		dests[filepath.Clean(e.Dest)] = true
+	}
+	var ids []string
+	for _, u := range list {
+		if u.ID == t.ID || u.State != TxCommitted || !u.CreatedAt.After(t.CreatedAt) {
+			continue
+		}
+		for _, e := range u.Entries {
+			if dests[filepath.Clean(e.Dest)] {
+				ids = append(ids, u.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// copyFile : copies src to dst through a temporary file and a rename, so
+// dst is never left half-written
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".hyprrelease-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+func sanitizeID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,124 @@
+package updateing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolate : points HOME and the XDG directories at a temporary directory
+func isolate(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	return home
+}
+
+// install : commits a transaction for dotfile writing data to every dest
+func install(t *testing.T, dotfile, data string, dests ...string) *Transaction {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.WriteFile(src, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := BeginTransaction(dotfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.w = &strings.Builder{}
+	for _, dest := range dests {
+		if err := tx.Stage(src, dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRollbackOrder(t *testing.T) {
+	home := isolate(t)
+	conf := filepath.Join(home, ".config", "hypr", "hyprland.conf")
+	other := filepath.Join(home, ".config", "waybar", "config")
+	if err := os.MkdirAll(filepath.Dir(conf), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(conf, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	opts := Options{Out: &out}
+	first := install(t, "first", "first", conf)
+	second := install(t, "second", "second", conf, other)
+	unrelated := install(t, "unrelated", "unrelated", filepath.Join(home, ".config", "kitty", "kitty.conf"))
+
+	// the first install's backup would overwrite the second install
+	err := Rollback(first.ID, opts)
+	if err == nil || !strings.Contains(err.Error(), second.ID) || strings.Contains(err.Error(), unrelated.ID) {
+		t.Fatalf("Rollback(first) = %v, want a refusal naming %s only", err, second.ID)
+	}
+	if got := readFile(t, conf); got != "second" {
+		t.Fatalf("refused rollback changed %s to %q", conf, got)
+	}
+
+	// newest first works, after which the first can go too
+	if err := Rollback(second.ID, opts); err != nil {
+		t.Fatalf("Rollback(second): %v", err)
+	}
+	if got := readFile(t, conf); got != "first" {
+		t.Errorf("%s after rolling back second = %q, want first", conf, got)
+	}
+	if got := readFile(t, other); got != "<missing>" {
+		t.Errorf("%s after rolling back second = %q, want it removed", other, got)
+	}
+	if err := Rollback(first.ID, opts); err != nil {
+		t.Fatalf("Rollback(first) after second: %v", err)
+	}
+	if !strings.Contains(out.String(), "transaction "+first.ID+" rolled back") {
+		t.Errorf("progress output not in Options.Out: %q", out.String())
+	}
+	if got := readFile(t, conf); got != "original" {
+		t.Errorf("%s after rolling back both = %q, want original", conf, got)
+	}
+
+	// only committed transactions can be rolled back
+	if err := Rollback(first.ID, opts); err == nil || !strings.Contains(err.Error(), TxRolledBack) {
+		t.Errorf("second Rollback(first) = %v, want it refused", err)
+	}
+	staged, err := BeginTransaction("staged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Rollback(staged.ID, opts); err == nil || !strings.Contains(err.Error(), TxStaged) {
+		t.Errorf("Rollback(staged) = %v, want it refused", err)
+	}
+
+	// without an id the latest committed transaction is rolled back
+	if err := Rollback("", opts); err != nil {
+		t.Fatalf("Rollback(latest): %v", err)
+	}
+	if tx, _ := LoadTransaction(unrelated.ID); tx.State != TxRolledBack {
+		t.Errorf("latest committed transaction is %s, want %s", tx.State, TxRolledBack)
+	}
+	if err := Rollback("", opts); err == nil {
+		t.Errorf("Rollback with nothing committed: no error")
+	}
+}
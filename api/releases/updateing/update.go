@@ -1,7 +1,9 @@
 package updateing
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hyprcommunity/hypr-release/api/releases/check"
@@ -9,28 +11,28 @@ import (
 )
 
 // UpdateDotfileAndSystem : dotfile + sistem bileşenlerini karşılaştırır ve gerekirse günceller
-func UpdateDotfileAndSystem(dotfileName string) error {
-	fmt.Printf("[hyprrelease-update] checking for updates: %s\n", dotfileName)
+func UpdateDotfileAndSystem(dotfileName string, opts Options) error {
+	fmt.Fprintf(opts.out(), "[hyprrelease-update] checking for updates: %s\n", dotfileName)
 
 	// Dotfile registry'den çekiliyor
-	var selected *summaryofversion.Dotfile
-	for _, d := range summaryofversion.Registry {
-		if d.Name == dotfileName {
-			selected = &d
-			break
-		}
+	selected, requested, err := resolveEntry(dotfileName, opts)
+	if err != nil {
+		return err
 	}
-	if selected == nil {
-		return fmt.Errorf("dotfile not found: %s", dotfileName)
+	migrated := true
+	if requested != nil {
+		if migrated, err = offerMetadataMigration(*requested, *selected, opts); err != nil {
+			return err
+		}
 	}
 
 	// Sistem bileşenlerini kontrol et
-	fmt.Println("[hyprrelease-update] scanning system components...")
-	components, logText, err := check.CheckHyprSystem()
+	fmt.Fprintln(opts.out(), "[hyprrelease-update] scanning system components...")
+	components, logText, err := check.CheckHyprSystem(context.Background(), check.SystemOptions{Plan: opts.Plan})
 	if err != nil {
-		fmt.Println("⚠️ failed to check system:", err)
+		fmt.Fprintln(opts.out(), "⚠️ failed to check system:", err)
 	} else {
-		fmt.Println(logText)
+		fmt.Fprintln(opts.out(), logText)
 	}
 
 	// Güncelleme olup olmadığını analiz et
@@ -44,9 +46,9 @@ func UpdateDotfileAndSystem(dotfileName string) error {
 
 	// Güncelleme varsa veya kullanıcı isterse Dotfile yeniden kurulabilir
 	if updatesAvailable {
-		fmt.Println("[hyprrelease-update] system updates detected.")
+		fmt.Fprintln(opts.out(), "[hyprrelease-update] system updates detected.")
 	} else {
-		fmt.Println("[hyprrelease-update] all system components up to date.")
+		fmt.Fprintln(opts.out(), "[hyprrelease-update] all system components up to date.")
 	}
 
 	// Metadata oluşturma
@@ -55,36 +57,71 @@ func UpdateDotfileAndSystem(dotfileName string) error {
 	branch := selected.Branch
 	releaseChannel := "stable"
 	commitsBehind := "0"
+	ref, commit := recordedRef(*selected, requested)
 
-	err = WriteMetaFile(
-		selected.Name,
-		versionMain,
-		versionBuild,
-		branch,
-		releaseChannel,
-		commitsBehind,
-	)
-	if err != nil {
-		fmt.Println("⚠️ failed to update metadata:", err)
-	} else {
-		fmt.Println("[hyprrelease-update] /etc/hyprland-release updated")
+	write := func(name, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit string) error {
+		return WriteMetaFile(opts.out(), name, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit)
+	}
+	if opts.dryRun() {
+		write = func(name, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit string) error {
+			return PlanMetaFile(opts.Plan, name, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit)
+		}
+	}
+	if migrated {
+		err = write(
+			selected.Name,
+			versionMain,
+			versionBuild,
+			branch,
+			releaseChannel,
+			commitsBehind,
+			ref,
+			commit,
+		)
+	}
+	if !migrated {
+		fmt.Fprintln(opts.out(), "[hyprrelease-update] metadata not updated")
+	} else if err != nil {
+		fmt.Fprintln(opts.out(), "⚠️ failed to update metadata:", err)
+	} else if !opts.dryRun() {
+		fmt.Fprintln(opts.out(), "[hyprrelease-update] /etc/hyprland-release updated")
 	}
 
-	// Kullanıcıdan onay al
-	fmt.Print("Do you want to reinstall or update this dotfile? [y/N]: ")
-	var resp string
-	fmt.Scanln(&resp)
-	if resp != "y" && resp != "Y" {
-		fmt.Println("[hyprrelease-update] skipped reinstall")
+	// Kullanıcıdan onay al; dry-run'da sorulmaz, yeniden kurulum planlanır
+	reinstall := true
+	if opts.dryRun() {
+		opts.Plan.Note("reinstalling is confirmed (%s) before it runs; the plan includes it", PromptUpdateReinstall)
+	} else if reinstall, err = opts.prompter().Confirm(PromptUpdateReinstall, "Do you want to reinstall or update this dotfile?", false); err != nil {
+		return fmt.Errorf("confirmation failed: %w", err)
+	}
+	if !reinstall {
+		fmt.Fprintln(opts.out(), "[hyprrelease-update] skipped reinstall")
 		return nil
 	}
 
 	// Yeniden kurulum
-	fmt.Println("[hyprrelease-update] reinstalling dotfile from registry...")
-	if err := InstallFromRegistry(dotfileName); err != nil {
+	fmt.Fprintln(opts.out(), "[hyprrelease-update] reinstalling dotfile from registry...")
+	if err := InstallFromRegistry(selected.Name, opts); err != nil {
 		return fmt.Errorf("installation failed: %v", err)
 	}
 
-	fmt.Println("[hyprrelease-update] done.")
+	fmt.Fprintln(opts.out(), "[hyprrelease-update] done.")
 	return nil
 }
+
+// recordedRef : ref ve commit, recordInstall'ın kaydettiği haliyle birlikte;
+// kayıt bu girdiye (ya da yönlendirilen eski girdiye) ait değilse registry
+// branch'i ve boş commit
+func recordedRef(selected summaryofversion.Dotfile, requested *summaryofversion.Dotfile) (ref, commit string) {
+	_, current := readMetaFile()
+	name := current["HYPRLAND_DOTFILES_NAME"]
+	if current["HYPRLAND_DOTFILES_COMMIT"] == "" ||
+		!strings.EqualFold(name, selected.Name) && (requested == nil || !strings.EqualFold(name, requested.Name)) {
+		return selected.Branch, ""
+	}
+	ref = current["HYPRLAND_DOTFILES_BRANCH"]
+	if ref == "" {
+		ref = selected.Branch
+	}
+	return ref, current["HYPRLAND_DOTFILES_COMMIT"]
+}
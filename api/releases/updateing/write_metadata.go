@@ -2,24 +2,80 @@ package updateing
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
 	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
 )
 
 // WriteMetaFile : Registry bilgileriyle hyprland-release metadata dosyasını oluşturur veya günceller.
-func WriteMetaFile(dotfileName string, versionMain, versionBuild, branch, releaseChannel, commitsBehind string) error {
-	d := summaryofversion.GetDotfileByName(dotfileName)
-	if d == nil {
-		return fmt.Errorf("dotfile not found: %s", dotfileName)
+// ref ve commit kurulu dotfile'ın ref'i ve commit'idir; ref boşsa registry branch'i yazılır.
+// İlerleme mesajları w'ye yazılır.
+func WriteMetaFile(w io.Writer, dotfileName string, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit string) error {
+	content, err := metaContent(dotfileName, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit)
+	if err != nil {
+		return err
+	}
+
+	// Önce kullanıcı dizinine yazmayı dene
+	userPath := userMetaPath()
+	os.MkdirAll(filepath.Dir(userPath), 0755)
+	if err := os.WriteFile(userPath, []byte(content), 0644); err == nil {
+		fmt.Fprintf(w, "[hyprrelease] metadata written to %s\n", userPath)
+		return nil
+	}
+
+	// Eğer başarısız olursa /etc altına yazmayı dene
+	if err := os.WriteFile(systemMetaPath, []byte(content), 0644); err == nil {
+		fmt.Fprintf(w, "[hyprrelease] metadata written to %s\n", systemMetaPath)
+		return nil
+	}
+
+	return fmt.Errorf("failed to write metadata to either %s or %s", userPath, systemMetaPath)
+}
+
+// PlanMetaFile : records what WriteMetaFile would write, without writing it
+func PlanMetaFile(p *plan.Plan, dotfileName string, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit string) error {
+	content, err := metaContent(dotfileName, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit)
+	if err != nil {
+		return err
+	}
+	p.AddMetadata(userMetaPath(), plan.KeysOf(content))
+	p.Note("if %s is not writable, metadata goes to %s", userMetaPath(), systemMetaPath)
+	return nil
+}
+
+const systemMetaPath = "/etc/hyprland-release"
+
+// userMetaPath : kullanıcı dizinine yazılacak varsayılan yol
+func userMetaPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "/tmp"
+	}
+	return filepath.Join(home, ".config", "hypr-release", "hyprland-release")
+}
+
+func metaContent(dotfileName string, versionMain, versionBuild, branch, releaseChannel, commitsBehind, ref, commit string) (string, error) {
+	entry := summaryofversion.GetDotfileByName(dotfileName)
+	if entry == nil {
+		return "", fmt.Errorf("dotfile not found: %s", dotfileName)
+	}
+	d, _ := entry.Moved()
+	if ref == "" {
+		ref = d.Branch
 	}
 
 	content := fmt.Sprintf(`# Hyprland Release Metadata
 HYPRLAND_DOTFILES_NAME="%s"
 HYPRLAND_DOTFILES_AUTHOR="%s"
 HYPRLAND_DOTFILES_BRANCH="%s"
+HYPRLAND_DOTFILES_COMMIT="%s"
 HYPRLAND_VERSION_MAIN="%s"
 HYPRLAND_VERSION_BUILD="%s"
 HYPRLAND_BRANCH="%s"
@@ -30,7 +86,8 @@ HYPRLAND_INSTALL_DATE="%s"
 `,
 		d.Name,
 		d.Author,
-		d.Branch,
+		ref,
+		commit,
 		versionMain,
 		versionBuild,
 		branch,
@@ -39,27 +96,88 @@ HYPRLAND_INSTALL_DATE="%s"
 		d.Repo,
 		time.Now().Format("2006-01-02 15:04:05"),
 	)
+	return content, nil
+}
 
-	// Kullanıcı dizinine yazılacak varsayılan yol
-	home, err := os.UserHomeDir()
-	if err != nil {
-		home = "/tmp"
+// recordInstall : stores what was installed (entry, ref and the exact
+// commit) in the metadata file, keeping its other keys, so an install can
+// be reproduced with --ref <commit>. With rename false the keys naming the
+// entry (name, author, remote URL) are left as they are, so a declined
+// metadata migration keeps pointing at the old entry.
+func recordInstall(d summaryofversion.Dotfile, ref, commit string, rename bool, opts Options) error {
+	path, _ := readMetaFile()
+	if path == "" {
+		path = userMetaPath()
 	}
-	userPath := filepath.Join(home, ".config", "hypr-release", "hyprland-release")
-	os.MkdirAll(filepath.Dir(userPath), 0755)
-
-	// Önce kullanıcı dizinine yazmayı dene
-	if err := os.WriteFile(userPath, []byte(content), 0644); err == nil {
-		fmt.Printf("[hyprrelease] metadata written to %s\n", userPath)
+	updates := map[string]string{
+		"HYPRLAND_DOTFILES_NAME":   d.Name,
+		"HYPRLAND_DOTFILES_AUTHOR": d.Author,
+		"HYPRLAND_DOTFILES_BRANCH": ref,
+		"HYPRLAND_DOTFILES_COMMIT": commit,
+		"HYPRLAND_REMOTE_URL":      d.Repo,
+		"HYPRLAND_INSTALL_DATE":    time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if !rename {
+		delete(updates, "HYPRLAND_DOTFILES_NAME")
+		delete(updates, "HYPRLAND_DOTFILES_AUTHOR")
+		delete(updates, "HYPRLAND_REMOTE_URL")
+	}
+	if opts.dryRun() {
+		opts.Plan.AddMetadata(path, sortedKeys(updates))
 		return nil
 	}
+	if err := updateMetaKeys(path, updates); err != nil {
+		return fmt.Errorf("failed to record install metadata: %v", err)
+	}
+	fmt.Fprintf(opts.out(), "[hyprrelease] recorded %s@%s in %s\n", d.Name, shortSHA(commit), path)
+	return nil
+}
 
-	// Eğer başarısız olursa /etc altına yazmayı dene
-	systemPath := "/etc/hyprland-release"
-	if err := os.WriteFile(systemPath, []byte(content), 0644); err == nil {
-		fmt.Printf("[hyprrelease] metadata written to %s\n", systemPath)
-		return nil
+// updateMetaKeys : rewrites the given keys in a KEY="value" metadata file,
+// appending missing ones and keeping every other line
+func updateMetaKeys(path string, updates map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	} else {
+		lines = []string{"# Hyprland Release Metadata"}
+	}
+	done := make(map[string]bool)
+	for i, line := range lines {
+		key, _, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if v, found := updates[key]; ok && found {
+			lines[i] = fmt.Sprintf("%s=%q", key, v)
+			done[key] = true
+		}
+	}
+	for _, k := range sortedKeys(updates) {
+		if !done[k] {
+			lines = append(lines, fmt.Sprintf("%s=%q", k, updates[k]))
+		}
 	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-	return fmt.Errorf("failed to write metadata to either %s or %s", userPath, systemPath)
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
 }
@@ -0,0 +1,315 @@
+// Package version : one comparable type for the version strings
+// hypr-release meets: semver tags (v0.45.2, 1.0.0-rc1), git describe output
+// (v0.45.2-9-g918d834), distro package versions (Arch 1:0.45.2-1, Debian
+// 0.45.2~rc1-1ubuntu2, RPM 0.45.2-1.fc41, Arch VCS 0.45.2.r9.g918d834-1)
+// and the --version banners of Hyprland and its tools.
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version : a parsed version. The zero value is "no version".
+type Version struct {
+	// Epoch : distro package epoch (the 1 in 1:0.45.2-1)
+	Epoch int `json:"epoch,omitempty"`
+	// Release : numeric components, e.g. [0 45 2]
+	Release []int `json:"release"`
+	// Pre : pre-release label (rc1, beta.2); sorts before the release
+	Pre string `json:"pre,omitempty"`
+	// Commits : commits after the tag (git describe, Arch VCS packages)
+	Commits int `json:"commits,omitempty"`
+	// Hash : abbreviated commit, when the input names one
+	Hash string `json:"hash,omitempty"`
+	// Revision : distro package revision (pkgrel, Debian revision, RPM release)
+	Revision string `json:"revision,omitempty"`
+	// Raw : the parsed input
+	Raw string `json:"raw"`
+}
+
+var (
+	describeRe = regexp.MustCompile(`^(.+)-(\d+)-g([0-9a-f]{4,40})(-dirty)?$`)
+	epochRe    = regexp.MustCompile(`^(\d+):(.+)$`)
+	releaseRe  = regexp.MustCompile(`^(\d+(?:\.\d+)*)(.*)$`)
+	// Arch VCS packages: 0.45.2.r9.g918d834 or 0.45.2+r9+g918d834
+	vcsRe     = regexp.MustCompile(`^[.+_]r(\d+)[.+_]g([0-9a-f]{4,40})(.*)$`)
+	gentooRev = regexp.MustCompile(`^-r\d+$`)
+	// a version inside free text, e.g. "Hyprland 0.45.2 built from ..."
+	inTextRe = regexp.MustCompile(`(?:^|[\s(,:])v?(\d+\.\d+(?:\.\d+)*(?:-[0-9A-Za-z.]+)*)`)
+	tagLine  = regexp.MustCompile(`(?m)^\s*Tag:\s*(\S+?),?(?:\s|$)`)
+	commitRe = regexp.MustCompile(`\bat commit ([0-9a-f]{7,40})\b`)
+)
+
+// Parse : parses a single version string. A leading "v" is ignored.
+func Parse(s string) (Version, error) {
+	raw := strings.TrimSpace(s)
+	v := Version{Raw: raw}
+	s = raw
+	if s == "" {
+		return v, fmt.Errorf("empty version")
+	}
+
+	if m := describeRe.FindStringSubmatch(s); m != nil {
+		base, err := Parse(m[1])
+		if err == nil {
+			base.Commits, _ = strconv.Atoi(m[2])
+			base.Hash = m[3]
+			base.Raw = raw
+			return base, nil
+		}
+	}
+	if m := epochRe.FindStringSubmatch(s); m != nil {
+		v.Epoch, _ = strconv.Atoi(m[1])
+		s = m[2]
+	}
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+
+	m := releaseRe.FindStringSubmatch(s)
+	if m == nil {
+		return v, fmt.Errorf("invalid version %q", raw)
+	}
+	for _, p := range strings.Split(m[1], ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", raw)
+		}
+		v.Release = append(v.Release, n)
+	}
+	rest := m[2]
+
+	if vm := vcsRe.FindStringSubmatch(rest); vm != nil {
+		v.Commits, _ = strconv.Atoi(vm[1])
+		v.Hash = vm[2]
+		rest = vm[3]
+	}
+	switch {
+	case rest == "":
+	case strings.HasPrefix(rest, "~"):
+		// Debian pre-release: 0.45.0~rc1-1
+		pre, rev, _ := strings.Cut(rest[1:], "-")
+		v.Pre, v.Revision = pre, rev
+	case strings.HasPrefix(rest, "+"):
+		// semver build metadata (ignored), maybe followed by a revision
+		if _, rev, ok := strings.Cut(rest, "-"); ok {
+			v.Revision = rev
+		}
+	case gentooRev.MatchString(rest):
+		v.Revision = rest[1:]
+	case strings.HasPrefix(rest, "-") || strings.HasPrefix(rest, "_"):
+		label := rest[1:]
+		if label == "" {
+			return v, fmt.Errorf("invalid version %q", raw)
+		}
+		if unicode.IsDigit(rune(label[0])) {
+			// package revision: pkgrel, Debian revision, RPM release
+			v.Revision = label
+		} else {
+			// pre-release, possibly followed by a package revision
+			pre, rev, _ := strings.Cut(label, "-")
+			v.Pre, v.Revision = strings.SplitN(pre, "+", 2)[0], rev
+		}
+	case unicode.IsLetter(rune(rest[0])):
+		// 0.45.0rc1, 1.2a
+		pre, rev, _ := strings.Cut(rest, "-")
+		v.Pre, v.Revision = pre, rev
+	default:
+		return v, fmt.Errorf("invalid version %q", raw)
+	}
+	return v, nil
+}
+
+// ParseBanner : version in --version output. Hyprland's banner carries a
+// "Tag: v0.45.2-9-g918d834, commits: ..." line, which is preferred;
+// otherwise the first version in the text is used ("hyprlock v0.4.0",
+// "Hyprland 0.45.2 built from branch ...").
+func ParseBanner(out string) (Version, error) {
+	out = strings.TrimSpace(out)
+	if m := tagLine.FindStringSubmatch(out); m != nil {
+		if v, err := Parse(m[1]); err == nil {
+			if v.Hash == "" {
+				if c := commitRe.FindStringSubmatch(out); c != nil {
+					v.Hash = c[1]
+				}
+			}
+			v.Raw = out
+			return v, nil
+		}
+	}
+	for _, m := range inTextRe.FindAllStringSubmatch(out, -1) {
+		if v, err := Parse(strings.TrimRight(m[1], ".")); err == nil {
+			if c := commitRe.FindStringSubmatch(out); c != nil && v.Hash == "" {
+				v.Hash = c[1]
+			}
+			v.Raw = out
+			return v, nil
+		}
+	}
+	return Version{Raw: out}, fmt.Errorf("no version found in %q", firstLine(out))
+}
+
+// ParseAny : Parse, falling back to ParseBanner for free text
+func ParseAny(s string) (Version, error) {
+	if v, err := Parse(s); err == nil {
+		return v, nil
+	}
+	return ParseBanner(s)
+}
+
+// IsZero : whether v holds no version
+func (v Version) IsZero() bool {
+	return len(v.Release) == 0
+}
+
+// Compare : full ordering: epoch, release, pre-release (before the
+// release), commits after the tag, then package revision. Use it for
+// versions from the same source, e.g. two pacman versions.
+func (v Version) Compare(w Version) int {
+	if c := cmpInt(v.Epoch, w.Epoch); c != 0 {
+		return c
+	}
+	if c := v.CompareUpstream(w); c != 0 {
+		return c
+	}
+	return compareSegments(v.Revision, w.Revision)
+}
+
+// CompareUpstream : ordering of the upstream version only (release,
+// pre-release, commits after the tag). Epochs and revisions are distro
+// specific, so this is the comparison between a tag, a banner and a
+// package of different origins.
+func (v Version) CompareUpstream(w Version) int {
+	for i := 0; i < len(v.Release) || i < len(w.Release); i++ {
+		var x, y int
+		if i < len(v.Release) {
+			x = v.Release[i]
+		}
+		if i < len(w.Release) {
+			y = w.Release[i]
+		}
+		if c := cmpInt(x, y); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case v.Pre == "" && w.Pre != "":
+		return 1
+	case v.Pre != "" && w.Pre == "":
+		return -1
+	}
+	if c := compareSegments(v.Pre, w.Pre); c != 0 {
+		return c
+	}
+	return cmpInt(v.Commits, w.Commits)
+}
+
+// Newer : whether v is a newer upstream version than w
+func (v Version) Newer(w Version) bool {
+	return v.CompareUpstream(w) > 0
+}
+
+// String : canonical form, e.g. 1:0.45.2-rc1-9-g918d834-1
+func (v Version) String() string {
+	if v.IsZero() {
+		return ""
+	}
+	var b strings.Builder
+	if v.Epoch != 0 {
+		fmt.Fprintf(&b, "%d:", v.Epoch)
+	}
+	for i, n := range v.Release {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	if v.Pre != "" {
+		b.WriteString("-" + v.Pre)
+	}
+	if v.Commits > 0 {
+		fmt.Fprintf(&b, "-%d", v.Commits)
+		if v.Hash != "" {
+			b.WriteString("-g" + v.Hash)
+		}
+	}
+	if v.Revision != "" {
+		b.WriteString("-" + v.Revision)
+	}
+	return b.String()
+}
+
+// Compare : compares two version strings (see ParseAny) by CompareUpstream
+func Compare(a, b string) (int, error) {
+	va, err := ParseAny(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseAny(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.CompareUpstream(vb), nil
+}
+
+// compareSegments : rpmvercmp-style comparison of labels such as "rc1",
+// "beta.2" or "1.fc41": runs of digits compare numerically, runs of
+// letters lexically, and a numeric run sorts after a letter run
+func compareSegments(a, b string) int {
+	for a != "" || b != "" {
+		a = strings.TrimLeftFunc(a, isSeparator)
+		b = strings.TrimLeftFunc(b, isSeparator)
+		if a == "" || b == "" {
+			return cmpInt(len(a), len(b))
+		}
+		var x, y string
+		x, a = nextRun(a)
+		y, b = nextRun(b)
+		xNum, yNum := unicode.IsDigit(rune(x[0])), unicode.IsDigit(rune(y[0]))
+		switch {
+		case xNum && !yNum:
+			return 1
+		case !xNum && yNum:
+			return -1
+		case xNum:
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if c := cmpInt(len(x), len(y)); c != 0 {
+				return c
+			}
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// nextRun : leading run of digits or of letters, and the rest
+func nextRun(s string) (string, string) {
+	digit := unicode.IsDigit(rune(s[0]))
+	i := 1
+	for i < len(s) && unicode.IsDigit(rune(s[i])) == digit && !isSeparator(rune(s[i])) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func firstLine(s string) string {
+	return strings.SplitN(s, "\n", 2)[0]
+}
@@ -0,0 +1,223 @@
+package version
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"0.45.2", Version{Release: []int{0, 45, 2}}},
+		{"v0.45", Version{Release: []int{0, 45}}},
+		// git describe
+		{"v0.45.2-9-g918d834", Version{Release: []int{0, 45, 2}, Commits: 9, Hash: "918d834"}},
+		{"v0.45.2-9-g918d834-dirty", Version{Release: []int{0, 45, 2}, Commits: 9, Hash: "918d834"}},
+		// Arch: epoch and pkgrel
+		{"1:0.45.2-1", Version{Epoch: 1, Release: []int{0, 45, 2}, Revision: "1"}},
+		// Debian: ~ pre-release, revision with a vendor suffix
+		{"0.45.2~rc1-1ubuntu2", Version{Release: []int{0, 45, 2}, Pre: "rc1", Revision: "1ubuntu2"}},
+		{"0.45.2+ds-1", Version{Release: []int{0, 45, 2}, Revision: "1"}},
+		// RPM release with dist tag
+		{"0.45.2-1.fc41", Version{Release: []int{0, 45, 2}, Revision: "1.fc41"}},
+		// Arch VCS packages
+		{"0.45.2.r9.g918d834-1", Version{Release: []int{0, 45, 2}, Commits: 9, Hash: "918d834", Revision: "1"}},
+		{"0.45.2+r9+g918d834-1", Version{Release: []int{0, 45, 2}, Commits: 9, Hash: "918d834", Revision: "1"}},
+		// Gentoo revision, Void revision
+		{"0.41.2-r1", Version{Release: []int{0, 41, 2}, Revision: "r1"}},
+		{"0.45.2_1", Version{Release: []int{0, 45, 2}, Revision: "1"}},
+		// semver pre-release and build metadata
+		{"1.0.0-rc1", Version{Release: []int{1, 0, 0}, Pre: "rc1"}},
+		{"1.0.0-beta.2+exp", Version{Release: []int{1, 0, 0}, Pre: "beta.2"}},
+		{"1.0.0+build.5", Version{Release: []int{1, 0, 0}}},
+		{"0.45.0rc1", Version{Release: []int{0, 45, 0}, Pre: "rc1"}},
+		{"0.45_rc2", Version{Release: []int{0, 45}, Pre: "rc2"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		tt.want.Raw = tt.in
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc1234", "nightly", "0.45.x", "1.0-", "1..2"} {
+		if v, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", in, v)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for in, want := range map[string]string{
+		"v0.45.2":              "0.45.2",
+		"1:0.45.2-1":           "1:0.45.2-1",
+		"0.45.2.r9.g918d834-1": "0.45.2-9-g918d834-1",
+		"0.45.2~rc1-1ubuntu2":  "0.45.2-rc1-1ubuntu2",
+	} {
+		if v, _ := Parse(in); v.String() != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", in, v.String(), want)
+		}
+	}
+	if s := (Version{}).String(); s != "" {
+		t.Errorf("zero Version = %q", s)
+	}
+}
+
+// TestCompare : each list is in ascending order
+func TestCompare(t *testing.T) {
+	for _, list := range [][]string{
+		// pre-releases before the release, commits after the tag
+		{"0.45.0-rc1", "0.45.0-rc2", "0.45.0-rc10", "0.45.0", "0.45.0-1-gabc1234", "0.45.0-2-gdef5678", "0.45.1"},
+		{"0.45.0~rc1-1", "0.45.0-1"},
+		{"0.9", "0.10", "0.10.1", "1.0.0-alpha", "1.0.0-beta", "1.0.0"},
+		// package revisions
+		{"0.45.2-1", "0.45.2-2", "0.45.2-10"},
+		{"0.45.2-1.fc40", "0.45.2-1.fc41", "0.45.2-2.fc40"},
+		{"0.41.2", "0.41.2-r1", "0.41.2-r2"},
+		// the epoch wins over the release
+		{"0.45.2-1", "1:0.40.0-1"},
+	} {
+		for i := 0; i+1 < len(list); i++ {
+			a, _ := Parse(list[i])
+			b, _ := Parse(list[i+1])
+			if c := a.Compare(b); c != -1 {
+				t.Errorf("Compare(%s, %s) = %d, want -1", list[i], list[i+1], c)
+			}
+			if c := b.Compare(a); c != 1 {
+				t.Errorf("Compare(%s, %s) = %d, want 1", list[i+1], list[i], c)
+			}
+		}
+	}
+
+	for _, pair := range [][2]string{{"0.45", "0.45.0"}, {"v0.45.2", "0.45.2"}, {"0.45.2-01", "0.45.2-1"}} {
+		a, _ := Parse(pair[0])
+		b, _ := Parse(pair[1])
+		if c := a.Compare(b); c != 0 {
+			t.Errorf("Compare(%s, %s) = %d, want 0", pair[0], pair[1], c)
+		}
+	}
+}
+
+func TestCompareUpstream(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		// epochs and revisions are distro specific
+		{"1:0.40.0-1", "0.45.2", -1},
+		{"0.45.2-3", "0.45.2-1.fc41", 0},
+		{"1:0.45.2-1", "v0.45.2", 0},
+		{"0.45.2.r9.g918d834-1", "v0.45.2-9-g918d834", 0},
+		{"0.45.2~rc1-1", "0.45.2", -1},
+		{"0.45.2.r9.g918d834-1", "0.45.2-1", 1},
+	}
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if c := a.CompareUpstream(b); c != tt.want {
+			t.Errorf("CompareUpstream(%s, %s) = %d, want %d", tt.a, tt.b, c, tt.want)
+		}
+		if tt.want > 0 != a.Newer(b) {
+			t.Errorf("Newer(%s, %s) = %t", tt.a, tt.b, a.Newer(b))
+		}
+	}
+
+	// Compare on strings accepts banners on either side
+	c, err := Compare("Hyprland 0.45.2 built from branch  at commit 12f9a0d (version: bump to 0.45.2).", "1:0.45.2-1")
+	if err != nil || c != 0 {
+		t.Errorf("Compare(banner, pacman) = %d, %v; want 0", c, err)
+	}
+	if _, err := Compare("0.45.2", "no version here"); err == nil {
+		t.Errorf("Compare with no version: no error")
+	}
+}
+
+func TestCompareSegments(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"rc1", "rc2", -1},
+		{"rc10", "rc9", 1},   // digit runs compare numerically
+		{"007", "7", 0},      // leading zeros are ignored
+		{"beta", "alpha", 1}, // letter runs compare lexically
+		{"1", "a", 1},        // a digit run sorts after a letter run
+		{"a", "1", -1},
+		{"beta.2", "beta.10", -1},
+		{"1.fc41", "1.fc40", 1},
+		{"1ubuntu2", "1ubuntu10", -1},
+		{"1.0", "1", 1}, // more segments sort after fewer
+		{"1", "", 1},
+		{"1_2", "1.2", 0}, // separators only split runs
+	}
+	for _, tt := range tests {
+		if c := compareSegments(tt.a, tt.b); c != tt.want {
+			t.Errorf("compareSegments(%q, %q) = %d, want %d", tt.a, tt.b, c, tt.want)
+		}
+	}
+}
+
+func TestParseBanner(t *testing.T) {
+	tests := []struct {
+		name, banner string
+		want         string
+		hash         string
+	}{
+		{
+			name: "0.34 dirty branch build",
+			banner: "Hyprland, built from branch main at commit 9ecbd4e1f8f34d8c8b5e7e8b0bd9df8f2a3c11c0 dirty (config: fix gaps).\n" +
+				"Tag: v0.34.0-12-g9ecbd4e1\n\nflags: (if any)\ndebug\n",
+			want: "0.34.0-12-g9ecbd4e1",
+			hash: "9ecbd4e1",
+		},
+		{
+			name: "0.41.2",
+			banner: "Hyprland, built from branch main at commit 918d8340afd652b011b937d29d5eea0be08467f5  ([gha] Nix: update inputs).\n" +
+				"Date: Wed Jun 26 08:23:43 2024\nTag: v0.41.2-9-g918d8340, commits: 4915\n\nflags: (if any)\n",
+			want: "0.41.2-9-g918d8340",
+			hash: "918d8340",
+		},
+		{
+			// the tag has no hash; the commit of the banner is used
+			name: "0.45.2 release",
+			banner: "Hyprland 0.45.2 built from branch  at commit 12f9a0d0b93f691d4d9923716557154d74777b0a  (version: bump to 0.45.2).\n" +
+				"Date: Sat Nov 16 14:54:18 2024\nTag: v0.45.2, commits: 5518\nbuilt against aquamarine 0.5.0\n\n\nflags set:\ndebug\n",
+			want: "0.45.2",
+			hash: "12f9a0d0b93f691d4d9923716557154d74777b0a",
+		},
+		{
+			name:   "head line only",
+			banner: "Hyprland 0.48.1 built from branch v0.48.1 at commit 29e2e59fdbab8ed2cc23a20e3c6043d5decb5cdc  (version: bump to 0.48.1).",
+			want:   "0.48.1",
+			hash:   "29e2e59fdbab8ed2cc23a20e3c6043d5decb5cdc",
+		},
+		{name: "hyprlock", banner: "hyprlock v0.4.0\n", want: "0.4.0"},
+		{name: "hyprpaper", banner: "Hyprpaper 0.7.1.", want: "0.7.1"},
+		{name: "hyprland-qtutils", banner: "hyprland-qtutils 0.1.2 (1.0)", want: "0.1.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseBanner(tt.banner)
+			if err != nil {
+				t.Fatalf("ParseBanner: %v", err)
+			}
+			if v.String() != tt.want || v.Hash != tt.hash {
+				t.Errorf("ParseBanner = %s (hash %q), want %s (hash %q)", v, v.Hash, tt.want, tt.hash)
+			}
+		})
+	}
+
+	for _, banner := range []string{"", "hyprctl: command not found", "built from branch main"} {
+		if v, err := ParseBanner(banner); err == nil {
+			t.Errorf("ParseBanner(%q) = %s, want an error", banner, v)
+		}
+	}
+}
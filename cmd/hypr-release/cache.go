@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/mirror"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+func runCache(args []string) int {
+	if len(args) == 0 {
+		return usageError("cache", "expected a subcommand")
+	}
+	switch args[0] {
+	case "list":
+		return runCacheList(args[1:])
+	case "update":
+		return runCacheUpdate(args[1:])
+	case "clean":
+		return runCacheClean(args[1:])
+	default:
+		return usageError("cache", "unknown subcommand %q", args[0])
+	}
+}
+
+func runCacheList(args []string) int {
+	fs := newFlagSet("cache")
+	asJSON := fs.Bool("json", false, "print cached repositories as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("cache", "unexpected arguments: %v", rest)
+	}
+
+	mirrors, err := mirror.List()
+	if err != nil {
+		return fail("cache", err)
+	}
+	if *asJSON {
+		return printJSON("cache", mirrors)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREF\tCOMMIT\tFETCHED\tSIZE\tREMOTE")
+	for _, m := range mirrors {
+		commit := m.Commit
+		if len(commit) > 10 {
+			commit = commit[:10]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f MB\t%s\n", m.Name, m.Ref, commit,
+			m.FetchedAt.Format("2006-01-02 15:04"), float64(m.SizeBytes)/1024/1024, m.Remote)
+	}
+	w.Flush()
+	return exitOK
+}
+
+func runCacheUpdate(args []string) int {
+	fs := newFlagSet("cache")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	names := rest
+	if len(names) == 0 {
+		mirrors, err := mirror.List()
+		if err != nil {
+			return fail("cache", err)
+		}
+		for _, m := range mirrors {
+			names = append(names, m.Name)
+		}
+	}
+	failed := 0
+	for _, name := range names {
+		d := summaryofversion.GetDotfileByName(name)
+		if d == nil {
+			fmt.Printf("⚠️ %s: not in the registry\n", name)
+			failed++
+			continue
+		}
+		moved, _ := d.Moved()
+		path, err := mirror.Sync(moved, "", mirror.Options{})
+		if err != nil {
+			fmt.Printf("⚠️ %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %s → %s\n", d.Name, path)
+	}
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func runCacheClean(args []string) int {
+	fs := newFlagSet("cache")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	names := rest
+	if len(names) == 0 {
+		mirrors, err := mirror.List()
+		if err != nil {
+			return fail("cache", err)
+		}
+		for _, m := range mirrors {
+			names = append(names, m.Name)
+		}
+	}
+	for _, name := range names {
+		if err := mirror.Remove(name); err != nil {
+			return fail("cache", err)
+		}
+		fmt.Println("🗑️ removed", mirror.Path(name))
+	}
+	return exitOK
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/check"
+	hyprjson "github.com/hyprcommunity/hypr-release/api/releases/check/json"
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
+)
+
+func runCheck(args []string) int {
+	if len(args) == 0 {
+		return usageError("check", "expected 'system' or 'release'")
+	}
+	switch args[0] {
+	case "system":
+		return runCheckSystem(args[1:])
+	case "release":
+		return runCheckRelease(args[1:])
+	default:
+		return usageError("check", "unknown check %q", args[0])
+	}
+}
+
+func runCheckSystem(args []string) int {
+	fs := newFlagSet("check")
+	asJSON := fs.Bool("json", false, "print components as JSON")
+	dryRun := fs.Bool("dry-run", false, "print what would be written to /etc/hyprland-system-release instead of writing it")
+	jobs := fs.Int("jobs", check.DefaultSystemWorkers, "number of components checked at once")
+	timeout := fs.Duration("timeout", check.DefaultSystemProbeTimeout, "time limit of each version, network and package manager query")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("check", "unexpected arguments: %v", rest)
+	}
+
+	if *jobs < 1 {
+		return usageError("check", "--jobs must be at least 1")
+	}
+	if *timeout <= 0 {
+		return usageError("check", "--timeout must be positive")
+	}
+
+	opts := check.SystemOptions{Workers: *jobs, ProbeTimeout: *timeout}
+	if *dryRun {
+		opts.Plan = plan.New("check", "system")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	components, logText, err := check.CheckHyprSystem(ctx, opts)
+	// with --json stdout is reserved for the JSON document
+	var log io.Writer = os.Stdout
+	if *asJSON {
+		log = os.Stderr
+	}
+	if err != nil {
+		// partial results: what was checked before the interrupt
+		fmt.Fprint(log, logText)
+		return fail("check", err)
+	}
+	if !*asJSON {
+		fmt.Print(logText)
+	}
+	if opts.Plan != nil {
+		fmt.Fprintln(log)
+		fmt.Fprint(log, opts.Plan.Text())
+	}
+	if *asJSON {
+		return printJSON("check", components)
+	}
+	return exitOK
+}
+
+func runCheckRelease(args []string) int {
+	fs := newFlagSet("check")
+	repoPath := fs.String("repo", "", "path to a clone of the dotfile repository (default: the repository cache)")
+	asJSON := fs.Bool("json", false, "print version info as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("check", "expected exactly one dotfile name")
+	}
+
+	result, logText, err := check.CheckAll(rest[0], *repoPath)
+	if err != nil {
+		return fail("check", err)
+	}
+	if *asJSON {
+		return printJSON("check", result)
+	}
+	fmt.Print(logText)
+	return exitOK
+}
+
+func runChannel(args []string) int {
+	fs := newFlagSet("channel")
+	repoPath := fs.String("repo", "", "path to a clone of the dotfile repository (default: the repository cache)")
+	asJSON := fs.Bool("json", false, "print the channel status as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("channel", "expected exactly one dotfile name")
+	}
+
+	status, logText, err := check.CheckTestingStatus(rest[0], *repoPath)
+	if err != nil {
+		return fail("channel", err)
+	}
+	if *asJSON {
+		return printJSON("channel", status)
+	}
+	fmt.Print(logText)
+	return exitOK
+}
+
+func runExport(args []string) int {
+	fs := newFlagSet("export")
+	outFile := fs.String("o", "", "write JSON to this file instead of stdout")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("export", "unexpected arguments: %v", rest)
+	}
+
+	data, err := hyprjson.ExportJSON()
+	if err != nil {
+		return fail("export", err)
+	}
+	if *outFile == "" {
+		fmt.Println(data)
+		return exitOK
+	}
+	if err := os.WriteFile(*outFile, []byte(data+"\n"), 0644); err != nil {
+		return fail("export", fmt.Errorf("failed to write %s: %v", *outFile, err))
+	}
+	return exitOK
+}
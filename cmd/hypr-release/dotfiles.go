@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+	"github.com/hyprcommunity/hypr-release/api/releases/updateing"
+)
+
+func runList(args []string) int {
+	fs := newFlagSet("list")
+	asJSON := fs.Bool("json", false, "print the registry as JSON")
+	tags := fs.String("tag", "", "only entries carrying all of these comma-separated tags")
+	distro := fs.String("distro", "", "only entries listing this os-release ID ('auto' for this system)")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("list", "unexpected arguments: %v", rest)
+	}
+
+	entries := filterEntries(summaryofversion.All(), *tags, *distro)
+	if *asJSON {
+		return printJSON("list", entries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tAUTHOR\tBRANCH\tRELEASES\tTAGS\tDISTROS\tDESCRIPTION")
+	for _, d := range entries {
+		desc := d.Description
+		if n := d.Notice(); n != "" {
+			desc = fmt.Sprintf("[%s] %s", n, desc)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n", d.Name, d.Author, d.Branch, d.HasReleases,
+			orDash(strings.Join(d.Tags, ",")), orDash(strings.Join(d.Distros, ",")), desc)
+	}
+	w.Flush()
+	return exitOK
+}
+
+// filterEntries : entries matching every tag in tags and the distro
+func filterEntries(entries []summaryofversion.Dotfile, tags, distro string) []summaryofversion.Dotfile {
+	f := summaryofversion.Filter{Tags: strings.Split(tags, ","), Distro: distro}
+	out := []summaryofversion.Dotfile{}
+	for _, d := range entries {
+		if f.Matches(d) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func runSearch(args []string) int {
+	fs := newFlagSet("search")
+	asJSON := fs.Bool("json", false, "print results as JSON")
+	author := fs.String("author", "", "only entries by this author")
+	tags := fs.String("tag", "", "only entries carrying all of these comma-separated tags")
+	distro := fs.String("distro", "", "only entries listing this os-release ID ('auto' for this system)")
+	releases := fs.String("releases", "", "'yes' or 'no': only entries with or without GitHub releases")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	f := summaryofversion.Filter{Author: *author, Tags: strings.Split(*tags, ","), Distro: *distro}
+	switch *releases {
+	case "":
+	case "yes", "no":
+		want := *releases == "yes"
+		f.HasReleases = &want
+	default:
+		return usageError("search", "--releases must be 'yes' or 'no'")
+	}
+
+	results := summaryofversion.Search(strings.Join(rest, " "), f)
+	if *asJSON {
+		return printJSON("search", results)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching dotfiles.")
+		return exitFailure
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tAUTHOR\tSCORE\tMATCH\tTAGS\tDESCRIPTION")
+	for _, r := range results {
+		d := r.Dotfile
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", d.Name, d.Author, r.Score, r.Match,
+			orDash(strings.Join(d.Tags, ",")), d.Description)
+	}
+	w.Flush()
+	return exitOK
+}
+
+func runShow(args []string) int {
+	fs := newFlagSet("show")
+	asJSON := fs.Bool("json", false, "print the entry as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("show", "expected exactly one dotfile name")
+	}
+
+	d := summaryofversion.GetDotfileByName(rest[0])
+	if d == nil {
+		return fail("show", summaryofversion.NotFoundError(rest[0]))
+	}
+	if *asJSON {
+		return printJSON("show", d)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range [][2]string{
+		{"Name", d.Name},
+		{"Author", d.Author},
+		{"Repo", d.Repo},
+		{"Branch", d.Branch},
+		{"Forge", d.Forge},
+		{"Releases", fmt.Sprint(d.HasReleases)},
+		{"Description", d.Description},
+		{"Tags", strings.Join(d.Tags, ", ")},
+		{"Distros", strings.Join(d.Distros, ", ")},
+		{"Packages", strings.Join(d.Packages, " ")},
+		{"Hyprland", d.HyprlandRange()},
+		{"License", d.License},
+		{"Homepage", d.Homepage},
+		{"Screenshots", strings.Join(d.Screenshots, ", ")},
+		{"Status", d.Notice()},
+		{"Source", d.Source},
+		{"Signed by", d.SignedBy},
+	} {
+		fmt.Fprintf(w, "%s:\t%s\n", row[0], orDash(row[1]))
+	}
+	w.Flush()
+	return exitOK
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func runInstall(args []string) int {
+	fs := newFlagSet("install")
+	pf := addPromptFlags(fs)
+	plf := addPlanFlags(fs)
+	offline := fs.Bool("offline", false, "use the repository cache as last fetched, without network access")
+	ref := fs.String("ref", "", "branch, tag or commit to install instead of the registry branch")
+	at := fs.String("at", "", "install the last commit before this date (YYYY-MM-DD or RFC 3339)")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("install", "expected exactly one dotfile name")
+	}
+
+	opts, err := pf.options()
+	if err != nil {
+		return fail("install", err)
+	}
+	opts.Plan = plf.new("install", rest[0])
+	opts.Out = plf.out()
+	opts.Offline = *offline
+	opts.Ref = *ref
+	if *at != "" {
+		if opts.At, err = updateing.ParseAt(*at); err != nil {
+			return usageError("install", "%v", err)
+		}
+	}
+	return plf.run("install", opts.Plan, func() error {
+		return updateing.InstallFromRegistry(rest[0], opts)
+	})
+}
+
+func runUpdate(args []string) int {
+	fs := newFlagSet("update")
+	pf := addPromptFlags(fs)
+	plf := addPlanFlags(fs)
+	offline := fs.Bool("offline", false, "use the repository cache as last fetched, without network access")
+	ref := fs.String("ref", "", "branch, tag or commit to install instead of the registry branch")
+	at := fs.String("at", "", "install the last commit before this date (YYYY-MM-DD or RFC 3339)")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("update", "expected exactly one dotfile name")
+	}
+
+	opts, err := pf.options()
+	if err != nil {
+		return fail("update", err)
+	}
+	opts.Plan = plf.new("update", rest[0])
+	opts.Out = plf.out()
+	opts.Offline = *offline
+	opts.Ref = *ref
+	if *at != "" {
+		if opts.At, err = updateing.ParseAt(*at); err != nil {
+			return usageError("update", "%v", err)
+		}
+	}
+	return plf.run("update", opts.Plan, func() error {
+		return updateing.UpdateDotfileAndSystem(rest[0], opts)
+	})
+}
+
+// printJSON : writes v to stdout as indented JSON
+func printJSON(name string, v any) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fail(name, fmt.Errorf("failed to encode JSON: %v", err))
+	}
+	fmt.Println(string(data))
+	return exitOK
+}
+
+func runUninstall(args []string) int {
+	fs := newFlagSet("uninstall")
+	force := fs.Bool("force", false, "also remove files modified since the install")
+	plf := addPlanFlags(fs)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("uninstall", "expected exactly one dotfile name")
+	}
+
+	opts := updateing.Options{Plan: plf.new("uninstall", rest[0]), Out: plf.out()}
+	return plf.run("uninstall", opts.Plan, func() error {
+		return updateing.Uninstall(rest[0], *force, opts)
+	})
+}
+
+func runRollback(args []string) int {
+	fs := newFlagSet("rollback")
+	list := fs.Bool("list", false, "list recorded transactions instead of rolling back")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) > 1 {
+		return usageError("rollback", "expected at most one transaction id")
+	}
+
+	if *list {
+		txs, err := updateing.ListTransactions(updateing.Options{})
+		if err != nil {
+			return fail("rollback", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tDOTFILE\tSTATE\tFILES\tCREATED")
+		for _, t := range txs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", t.ID, t.Dotfile, t.State, len(t.Entries), t.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+		return exitOK
+	}
+
+	id := ""
+	if len(rest) == 1 {
+		id = rest[0]
+	}
+	if err := updateing.Rollback(id, updateing.Options{}); err != nil {
+		return fail("rollback", err)
+	}
+	return exitOK
+}
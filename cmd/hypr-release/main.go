@@ -0,0 +1,158 @@
+// hypr-release : Hyprland dotfile and system release manager (CLI).
+//
+// The CLI is a thin layer over the api/releases packages so that every
+// operation the Fyne GUI offers can also be scripted.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes shared by every subcommand.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command : a single hypr-release subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"list", "list [--json] [--tag <t,...>] [--distro <id>|auto]", "list dotfiles in the registry", runList},
+		{"show", "show [--json] <name>", "show the registry entry of a dotfile", runShow},
+		{"search", "search [--json] [--author <a>] [--tag <t,...>] [--distro <id>|auto] [--releases yes|no] [<query>...]", "search the registry by fuzzy name, description and filters", runSearch},
+		{"registry", "registry list|update|sources [--json] | validate [--json] [--max-age <days>] [--source <s>] [<name>...] | add [--yes] [--name <n>] [--description <d>] [--replace] [--json] <git-url|path> | keygen [--dir <dir>] <id> | sign --key <file> <index>...", "show registry entries and sources, refresh the index cache, check entry health, add entries, sign indexes", runRegistry},
+		{"install", "install [--yes] [--answers <file>] [--dry-run [--plan-json]] [--offline] [--ref <ref>] [--at <date>] <name>", "fetch and install a dotfile from the registry", runInstall},
+		{"update", "update [--yes] [--answers <file>] [--dry-run [--plan-json]] [--offline] [--ref <ref>] [--at <date>] <name>", "check system components and update a dotfile", runUpdate},
+		{"uninstall", "uninstall [--force] [--dry-run [--plan-json]] <name>", "remove the files a dotfile install placed and restore the originals", runUninstall},
+		{"rollback", "rollback [--list] [<transaction-id>]", "undo the latest (or the given) install transaction", runRollback},
+		{"check", "check system [--dry-run] [--json] [--jobs <n>] [--timeout <duration>] | check release <name> [--repo <path>] [--json]", "check Hyprland components or a dotfile release", runCheck},
+		{"channel", "channel <name> [--repo <path>] [--json]", "detect the release channel of a dotfile", runChannel},
+		{"cache", "cache list [--json] | update [<name>...] | clean [<name>...]", "manage the local repository cache", runCache},
+		{"export", "export [-o <file>]", "export release and system metadata as JSON", runExport},
+		{"models", "models list [--available] | models install [--answers <file>] [<model>]", "manage local LLM models", runModels},
+		{"help", "help [<command>]", "show help for a command", runHelp},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "-h", "--help":
+		printUsage(os.Stdout)
+		return exitOK
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "hypr-release: unknown command %q\n\n", args[0])
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	return cmd.run(args[1:])
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: hypr-release <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'hypr-release help <command>' for details.")
+}
+
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "hypr-release: unknown command %q\n", args[0])
+		return exitUsage
+	}
+	fmt.Printf("Usage: hypr-release %s\n\n%s\n", cmd.usage, cmd.summary)
+	return exitOK
+}
+
+// newFlagSet : flag set whose usage line matches the command table
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		if cmd := findCommand(name); cmd != nil {
+			fmt.Fprintf(fs.Output(), "Usage: hypr-release %s\n", cmd.usage)
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs : parses flags that may appear before or after positional
+// arguments and returns the positional arguments in order.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// usageError : reports a usage problem and returns the usage exit code
+func usageError(name, format string, a ...any) int {
+	fmt.Fprintf(os.Stderr, "hypr-release %s: %s\n", name, fmt.Sprintf(format, a...))
+	if cmd := findCommand(name); cmd != nil {
+		fmt.Fprintf(os.Stderr, "Usage: hypr-release %s\n", cmd.usage)
+	}
+	return exitUsage
+}
+
+// flagExit : exit code for a flag parsing error
+func flagExit(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// fail : prints an operation error and returns the failure exit code
+func fail(name string, err error) int {
+	fmt.Fprintf(os.Stderr, "hypr-release %s: %v\n", name, strings.TrimSpace(err.Error()))
+	return exitFailure
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/updateing"
+)
+
+func runModels(args []string) int {
+	if len(args) == 0 {
+		return usageError("models", "expected 'list' or 'install'")
+	}
+	switch args[0] {
+	case "list":
+		return runModelsList(args[1:])
+	case "install":
+		return runModelsInstall(args[1:])
+	default:
+		return usageError("models", "unknown subcommand %q", args[0])
+	}
+}
+
+func runModelsList(args []string) int {
+	fs := newFlagSet("models")
+	available := fs.Bool("available", false, "list downloadable models instead of installed ones")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("models", "unexpected arguments: %v", rest)
+	}
+
+	if !*available {
+		updateing.ListInstalledModels()
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tDESCRIPTION")
+	for _, m := range updateing.ListAvailableModels() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", m.Name, m.Size, m.Desc)
+	}
+	w.Flush()
+	return exitOK
+}
+
+func runModelsInstall(args []string) int {
+	fs := newFlagSet("models")
+	pf := addPromptFlags(fs)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	switch len(rest) {
+	case 0:
+		p, err := pf.prompter()
+		if err != nil {
+			return fail("models", err)
+		}
+		updateing.SelectAndInstallModel(p)
+		return exitOK
+	case 1:
+	default:
+		return usageError("models", "expected at most one model name")
+	}
+
+	for _, m := range updateing.ListAvailableModels() {
+		if m.Name == rest[0] {
+			if err := updateing.DownloadModel(m); err != nil {
+				return fail("models", err)
+			}
+			return exitOK
+		}
+	}
+	return fail("models", fmt.Errorf("model not found: %s", rest[0]))
+}
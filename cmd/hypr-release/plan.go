@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/plan"
+)
+
+// planFlags : --dry-run / --plan-json flags of mutating commands
+type planFlags struct {
+	dryRun *bool
+	asJSON *bool
+}
+
+func addPlanFlags(fs *flag.FlagSet) planFlags {
+	return planFlags{
+		dryRun: fs.Bool("dry-run", false, "print what would change instead of changing it"),
+		asJSON: fs.Bool("plan-json", false, "with --dry-run, print the plan as JSON"),
+	}
+}
+
+// new : plan to fill, or nil when not a dry run
+func (f planFlags) new(operation, target string) *plan.Plan {
+	if !*f.dryRun && !*f.asJSON {
+		return nil
+	}
+	return plan.New(operation, target)
+}
+
+// out : where the operation's progress output goes. For JSON plans it is
+// stderr, so stdout carries only JSON.
+func (f planFlags) out() io.Writer {
+	if *f.asJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// run : runs op and prints the plan it filled
+func (f planFlags) run(name string, p *plan.Plan, op func() error) int {
+	err := op()
+	if err != nil {
+		return fail(name, err)
+	}
+	if p == nil {
+		return exitOK
+	}
+
+	if !*f.asJSON {
+		fmt.Println()
+		fmt.Print(p.Text())
+		return exitOK
+	}
+	data, err := p.JSON()
+	if err != nil {
+		return fail(name, err)
+	}
+	fmt.Println(data)
+	return exitOK
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/prompt"
+	"github.com/hyprcommunity/hypr-release/api/releases/updateing"
+)
+
+// promptFlags : --yes / --answers flags shared by every command that asks questions
+type promptFlags struct {
+	yes     *bool
+	answers *string
+}
+
+func addPromptFlags(fs *flag.FlagSet) promptFlags {
+	return promptFlags{
+		yes:     fs.Bool("yes", false, "assume yes for every confirmation and keep defaults"),
+		answers: fs.String("answers", "", "read answers from a KEY=value file (see updateing.Prompt* keys)"),
+	}
+}
+
+// prompter : terminal by default; answers file and --yes make the run unattended
+func (f promptFlags) prompter() (prompt.Prompter, error) {
+	var fallback prompt.Prompter = prompt.Stdio()
+	if *f.yes {
+		fallback = prompt.AssumeYes{}
+	}
+	if *f.answers == "" {
+		return fallback, nil
+	}
+	a, err := prompt.LoadAnswers(*f.answers)
+	if err != nil {
+		return nil, err
+	}
+	if *f.yes {
+		a.Fallback = fallback
+	}
+	return a, nil
+}
+
+func (f promptFlags) options() (updateing.Options, error) {
+	p, err := f.prompter()
+	if err != nil {
+		return updateing.Options{}, err
+	}
+	return updateing.Options{Prompter: p}, nil
+}
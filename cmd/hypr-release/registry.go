@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hyprcommunity/hypr-release/api/releases/scaffold"
+	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
+)
+
+func runRegistry(args []string) int {
+	if len(args) == 0 {
+		return usageError("registry", "expected a subcommand")
+	}
+	switch args[0] {
+	case "list":
+		return runRegistryList(args[1:])
+	case "update":
+		return runRegistryUpdate(args[1:])
+	case "sources":
+		return runRegistrySources(args[1:])
+	case "validate":
+		return runRegistryValidate(args[1:])
+	case "add":
+		return runRegistryAdd(args[1:])
+	case "keygen":
+		return runRegistryKeygen(args[1:])
+	case "sign":
+		return runRegistrySign(args[1:])
+	default:
+		return usageError("registry", "unknown subcommand %q", args[0])
+	}
+}
+
+func runRegistryList(args []string) int {
+	fs := newFlagSet("registry")
+	asJSON := fs.Bool("json", false, "print entries as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("registry", "unexpected arguments: %v", rest)
+	}
+
+	entries := summaryofversion.All()
+	if *asJSON {
+		return printJSON("registry", entries)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tBRANCH\tREPO")
+	for _, d := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Name, d.Source, d.Branch, d.Repo)
+	}
+	w.Flush()
+	return exitOK
+}
+
+func runRegistryUpdate(args []string) int {
+	fs := newFlagSet("registry")
+	asJSON := fs.Bool("json", false, "print source statuses as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("registry", "unexpected arguments: %v", rest)
+	}
+
+	statuses, err := summaryofversion.UpdateSources()
+	if err != nil {
+		return fail("registry", err)
+	}
+	failed := 0
+	for _, st := range statuses {
+		if st.Err != nil {
+			failed++
+		}
+	}
+	if *asJSON {
+		if code := printJSON("registry", statuses); code != exitOK {
+			return code
+		}
+	} else {
+		for _, st := range statuses {
+			if st.Err != nil {
+				fmt.Printf("⚠️ %s (%s): %v\n", st.Source.Name, st.Source.Type, st.Err)
+				continue
+			}
+			fmt.Printf("✅ %s (%s): %d entries%s\n", st.Source.Name, st.Source.Type, st.Entries, signedNote(st.SignedBy))
+		}
+	}
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func runRegistrySources(args []string) int {
+	fs := newFlagSet("registry")
+	asJSON := fs.Bool("json", false, "print sources as JSON")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 0 {
+		return usageError("registry", "unexpected arguments: %v", rest)
+	}
+
+	sources, err := summaryofversion.Sources()
+	if err != nil {
+		return fail("registry", err)
+	}
+	if *asJSON {
+		return printJSON("registry", sources)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tPRIORITY\tSIGNED BY\tURL")
+	for _, s := range sources {
+		signed := "-"
+		if signers, err := summaryofversion.VerifySource(s); err != nil {
+			signed = "⚠️ unverified"
+		} else if len(signers) > 0 {
+			signed = strings.Join(signers, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", s.Name, s.Type, s.Priority, signed, s.URL)
+	}
+	w.Flush()
+	return exitOK
+}
+
+func runRegistryValidate(args []string) int {
+	fs := newFlagSet("registry")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	maxAge := fs.Int("max-age", 365, "warn when the last commit is older than this many days")
+	source := fs.String("source", "", "only check entries from this registry source")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	var entries []summaryofversion.Dotfile
+	if len(rest) > 0 {
+		for _, name := range rest {
+			d := summaryofversion.GetDotfileByName(name)
+			if d == nil {
+				return fail("registry", summaryofversion.NotFoundError(name))
+			}
+			entries = append(entries, *d)
+		}
+	} else {
+		for _, d := range summaryofversion.All() {
+			if *source == "" || d.Source == *source {
+				entries = append(entries, d)
+			}
+		}
+	}
+
+	report := scaffold.ValidateRegistry(entries, scaffold.ValidateOptions{MaxAge: time.Duration(*maxAge) * 24 * time.Hour})
+	if *asJSON {
+		if code := printJSON("registry", report); code != exitOK {
+			return code
+		}
+	} else {
+		for _, e := range report.Entries {
+			if len(e.Issues) == 0 {
+				fmt.Printf("✅ %s (%s@%s)\n", e.Name, e.Repo, e.Branch)
+				continue
+			}
+			fmt.Printf("%s (%s@%s)\n", e.Name, e.Repo, e.Branch)
+			for _, is := range e.Issues {
+				mark := "⚠️"
+				if is.Severity == scaffold.SeverityError {
+					mark = "❌"
+				}
+				fmt.Printf("  %s %s: %s\n", mark, is.Check, is.Message)
+			}
+		}
+		fmt.Printf("\n%d entries checked: %d errors, %d warnings\n", len(report.Entries), report.Errors, report.Warnings)
+	}
+	if report.Errors > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func runRegistryAdd(args []string) int {
+	fs := newFlagSet("registry")
+	pf := addPromptFlags(fs)
+	name := fs.String("name", "", "override the proposed entry name")
+	description := fs.String("description", "", "override the proposed description")
+	replace := fs.Bool("replace", false, "replace an existing user entry with the same name")
+	asJSON := fs.Bool("json", false, "print the proposal as JSON and write nothing")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("registry", "add expects exactly one git URL or local path")
+	}
+
+	proposal, err := scaffold.Inspect(rest[0])
+	if proposal != nil {
+		if *name != "" {
+			proposal.Entry.Name = *name
+		}
+		if *description != "" {
+			proposal.Entry.Description = *description
+		}
+		if *name != "" || *description != "" {
+			err = summaryofversion.Validate(proposal.Entry)
+		}
+	}
+	if err != nil {
+		return fail("registry", err)
+	}
+	if *asJSON {
+		return printJSON("registry", proposal)
+	}
+
+	block, err := summaryofversion.EncodeEntry(proposal.Entry)
+	if err != nil {
+		return fail("registry", err)
+	}
+	fmt.Println("[hyprrelease] proposed registry entry:")
+	fmt.Println()
+	fmt.Println(block)
+	for _, n := range proposal.Notes {
+		fmt.Println("  →", n)
+	}
+	if existing := summaryofversion.GetDotfileByName(proposal.Entry.Name); existing != nil && existing.Source != "user" {
+		fmt.Printf("⚠️ %s already exists in source %s; the user entry will take precedence\n", existing.Name, existing.Source)
+	}
+	fmt.Println()
+
+	p, err := pf.prompter()
+	if err != nil {
+		return fail("registry", err)
+	}
+	ok, err := p.Confirm(scaffold.PromptAddConfirm, "Write this entry to "+summaryofversion.UserRegistryFile()+"?", true)
+	if err != nil {
+		return fail("registry", err)
+	}
+	if !ok {
+		fmt.Println("Nothing written.")
+		return exitOK
+	}
+	path, err := summaryofversion.AddUserEntry(proposal.Entry, *replace)
+	if err != nil {
+		return fail("registry", err)
+	}
+	fmt.Printf("✅ %s added to %s\n", proposal.Entry.Name, path)
+	return exitOK
+}
+
+func signedNote(signers []string) string {
+	if len(signers) == 0 {
+		return ""
+	}
+	return ", signed by " + strings.Join(signers, ",")
+}
+
+func runRegistryKeygen(args []string) int {
+	fs := newFlagSet("registry")
+	dir := fs.String("dir", ".", "directory to write <id>.pub and <id>.key to")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if len(rest) != 1 {
+		return usageError("registry", "keygen expects exactly one key id")
+	}
+
+	pub, priv, err := summaryofversion.GenerateKey()
+	if err != nil {
+		return fail("registry", err)
+	}
+	pubPath := filepath.Join(*dir, rest[0]+".pub")
+	privPath := filepath.Join(*dir, rest[0]+".key")
+	for _, p := range []string{pubPath, privPath} {
+		if _, err := os.Stat(p); err == nil {
+			return fail("registry", fmt.Errorf("%s already exists", p))
+		}
+	}
+	if err := os.WriteFile(privPath, priv, 0600); err != nil {
+		return fail("registry", err)
+	}
+	if err := os.WriteFile(pubPath, pub, 0644); err != nil {
+		return fail("registry", err)
+	}
+	fmt.Println("✅ signing key:", privPath)
+	fmt.Println("✅ public key: ", pubPath)
+	fmt.Println("→ install the public key into one of:", strings.Join(summaryofversion.TrustedKeyDirs(), ", "))
+	return exitOK
+}
+
+func runRegistrySign(args []string) int {
+	fs := newFlagSet("registry")
+	keyPath := fs.String("key", "", "signing key written by 'registry keygen'")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return flagExit(err)
+	}
+	if *keyPath == "" || len(rest) == 0 {
+		return usageError("registry", "sign expects --key <file> and at least one index file")
+	}
+
+	priv, err := summaryofversion.LoadPrivateKey(*keyPath)
+	if err != nil {
+		return fail("registry", err)
+	}
+	for _, index := range rest {
+		if _, err := summaryofversion.LoadRegistryFile(index); err != nil {
+			return fail("registry", err)
+		}
+		data, err := os.ReadFile(index)
+		if err != nil {
+			return fail("registry", err)
+		}
+		if err := os.WriteFile(index+summaryofversion.SigSuffix, summaryofversion.SignIndex(priv, data), 0644); err != nil {
+			return fail("registry", err)
+		}
+		fmt.Println("✅ signed", index)
+	}
+	return exitOK
+}
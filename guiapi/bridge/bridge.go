@@ -1,6 +1,7 @@
 package bridge
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/hyprcommunity/hypr-release/api/releases/check"
+	"github.com/hyprcommunity/hypr-release/api/releases/prompt"
 	"github.com/hyprcommunity/hypr-release/api/releases/summaryofversion"
 	"github.com/hyprcommunity/hypr-release/api/releases/updateing"
 )
@@ -18,12 +20,19 @@ import (
 // GUI yalnızca bu interface ile konuşur.
 type Bridge struct {
 	ReleasePath string
+	// Prompter install/update sırasındaki onay ve seçimleri yanıtlar.
+	// GUI bunu dialog tabanlı bir prompter ile değiştirir; terminal
+	// girdisi beklenmez.
+	Prompter prompt.Prompter
 }
 
-// NewBridge: Varsayılan bir Bridge oluşturur.
+// NewBridge: Varsayılan bir Bridge oluşturur. Varsayılan Prompter hiçbir
+// soruyu yanıtlamaz: README komutları ve AI kopyası gibi onaylar, çağıran
+// kendi Prompter'ını atamadıkça hata ile sonuçlanır.
 func NewBridge() *Bridge {
 	return &Bridge{
 		ReleasePath: "/etc/hyprland-release",
+		Prompter:    &prompt.Answers{},
 	}
 }
 
@@ -31,17 +40,24 @@ func NewBridge() *Bridge {
 // ──────────────────────────── 1. SYSTEM CHECK ────────────────────────────
 //
 
-// SystemInfo: sistem bileşenleri ve log çıktısını döndürür.
+// systemCheckTimeout: GUI'nin sistem kontrolünü beklediği en uzun süre
+const systemCheckTimeout = 2 * time.Minute
+
+// SystemInfo: sistem bileşenleri ve log çıktısını döndürür. Kontrol
+// systemCheckTimeout içinde bitmezse o ana kadarki sonuçlar "error"
+// alanıyla döner.
 func (b *Bridge) SystemInfo() (string, error) {
-	components, logText, err := check.CheckHyprSystem()
-	if err != nil {
-		return "", fmt.Errorf("system check failed: %v", err)
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), systemCheckTimeout)
+	defer cancel()
+	components, logText, err := check.CheckHyprSystem(ctx, check.SystemOptions{})
 
 	result := map[string]any{
 		"components": components,
 		"log":        logText,
 	}
+	if err != nil {
+		result["error"] = fmt.Sprintf("system check failed: %v", err)
+	}
 	data, _ := json.MarshalIndent(result, "", "  ")
 	return string(data), nil
 }
@@ -52,21 +68,55 @@ func (b *Bridge) SystemInfo() (string, error) {
 
 // DotfileEntry: GUI'de gösterilecek sade model
 type DotfileEntry struct {
-	Name    string `json:"name"`
-	Author  string `json:"author"`
-	RepoURL string `json:"repo"`
-	Branch  string `json:"branch"`
+	Name        string   `json:"name"`
+	Author      string   `json:"author"`
+	RepoURL     string   `json:"repo"`
+	Branch      string   `json:"branch"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Distros     []string `json:"distros,omitempty"`
+	Packages    []string `json:"packages,omitempty"`
+	Hyprland    string   `json:"hyprland,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Screenshots []string `json:"screenshots,omitempty"`
+	// Notice: deprecated / replaced by / moved to uyarısı
+	Notice string `json:"notice,omitempty"`
 }
 
 // GetDotfiles: Registry listesini döndürür.
 func (b *Bridge) GetDotfiles() ([]DotfileEntry, error) {
+	return b.FilterDotfiles("", "")
+}
+
+// FilterDotfiles: tag ve distro'ya göre süzülmüş registry listesini döndürür.
+// Boş değerler filtre uygulamaz; distro "auto" ise bu sistemin os-release
+// kimlikleri kullanılır.
+func (b *Bridge) FilterDotfiles(tag, distro string) ([]DotfileEntry, error) {
+	return b.SearchDotfiles("", tag, distro)
+}
+
+// SearchDotfiles: fuzzy isim ve açıklama araması; sonuçlar en iyi eşleşme
+// önce gelecek şekilde sıralanır.
+func (b *Bridge) SearchDotfiles(query, tag, distro string) ([]DotfileEntry, error) {
+	f := summaryofversion.Filter{Tags: []string{tag}, Distro: distro}
 	var entries []DotfileEntry
-	for _, d := range summaryofversion.Registry {
+	for _, r := range summaryofversion.Search(query, f) {
+		d := r.Dotfile
 		entries = append(entries, DotfileEntry{
-			Name:    d.Name,
-			Author:  d.Author,
-			RepoURL: d.Repo,
-			Branch:  d.Branch,
+			Name:        d.Name,
+			Author:      d.Author,
+			RepoURL:     d.Repo,
+			Branch:      d.Branch,
+			Description: d.Description,
+			Tags:        d.Tags,
+			Distros:     d.Distros,
+			Packages:    d.Packages,
+			Hyprland:    d.HyprlandRange(),
+			License:     d.License,
+			Homepage:    d.Homepage,
+			Screenshots: d.Screenshots,
+			Notice:      d.Notice(),
 		})
 	}
 	return entries, nil
@@ -79,7 +129,7 @@ func (b *Bridge) GetDotfiles() ([]DotfileEntry, error) {
 // InstallDotfile: seçili dotfile’ı kurar.
 func (b *Bridge) InstallDotfile(name string) error {
 	fmt.Printf("[install] installing %s...\n", name)
-	if err := updateing.InstallFromRegistry(name); err != nil {
+	if err := updateing.InstallFromRegistry(name, b.options()); err != nil {
 		return fmt.Errorf("install failed: %v", err)
 	}
 	return nil
@@ -88,17 +138,33 @@ func (b *Bridge) InstallDotfile(name string) error {
 // UpdateDotfile: mevcut dotfile’ı günceller.
 func (b *Bridge) UpdateDotfile(name string) error {
 	fmt.Printf("[update] updating %s...\n", name)
-	if err := updateing.UpdateDotfileAndSystem(name); err != nil {
+	if err := updateing.UpdateDotfileAndSystem(name, b.options()); err != nil {
 		return fmt.Errorf("update failed: %v", err)
 	}
 	return nil
 }
 
+// UninstallDotfile: manifest'e göre dotfile dosyalarını kaldırır.
+func (b *Bridge) UninstallDotfile(name string) error {
+	fmt.Printf("[uninstall] removing %s...\n", name)
+	if err := updateing.Uninstall(name, false, b.options()); err != nil {
+		return fmt.Errorf("uninstall failed: %v", err)
+	}
+	return nil
+}
+
+// options: backend çağrıları için Bridge'in prompter'ını taşır.
+func (b *Bridge) options() updateing.Options {
+	return updateing.Options{Prompter: b.Prompter}
+}
+
 //
 // ──────────────────────────── 4. RELEASE / VERSION ────────────────────────────
 //
 
-// CheckRelease: GitHub ve git tag’leri üzerinden versiyon bilgisi alır.
+// CheckRelease: forge release’leri ve git tag’leri üzerinden versiyon bilgisini,
+// upstream’e göre ahead/behind ve dirty durumunu alır.
+// repoPath boşsa dotfile'ın depo önbelleği kullanılır.
 func (b *Bridge) CheckRelease(dotfileName, repoPath string) (string, error) {
 	result, logText, err := check.CheckAll(dotfileName, repoPath)
 	if err != nil {
@@ -16,6 +16,7 @@ func main() {
 
 	// Bridge örneğini oluştur
 	b := bridge.NewBridge()
+	b.Prompter = ui.NewDialogPrompter(w)
 
 	tabs := container.NewAppTabs(
 		container.NewTabItem("System", ui.NewSystemTab(w, b)),
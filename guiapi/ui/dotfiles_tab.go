@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hyprcommunity/hypr-release/guiapi/bridge"
 	"fyne.io/fyne/v2"
@@ -19,6 +20,8 @@ func NewDotfilesTab(win fyne.Window, b *bridge.Bridge) fyne.CanvasObject {
 				widget.NewLabel("Name"),
 				widget.NewLabel("Author"),
 				widget.NewLabel("Branch"),
+				widget.NewLabel("Tags"),
+				widget.NewLabel("Requirements"),
 				widget.NewButton("Install", nil),
 				widget.NewButton("Update", nil),
 			)
@@ -26,8 +29,16 @@ func NewDotfilesTab(win fyne.Window, b *bridge.Bridge) fyne.CanvasObject {
 		func(i widget.ListItemID, o fyne.CanvasObject) {},
 	)
 
+	// Arama ve filtreler: tag ve distro ("auto" = bu sistem)
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search dotfiles...")
+	tagFilter := widget.NewEntry()
+	tagFilter.SetPlaceHolder("Tag (minimal, animated...)")
+	distroFilter := widget.NewSelect([]string{"", "auto", "arch", "fedora", "debian", "ubuntu", "opensuse", "nixos"}, nil)
+	distroFilter.PlaceHolder = "Distro"
+
 	refresh := widget.NewButton("Load Dotfiles", func() {
-		entries, err := b.GetDotfiles()
+		entries, err := b.SearchDotfiles(strings.TrimSpace(search.Text), strings.TrimSpace(tagFilter.Text), distroFilter.Selected)
 		if err != nil {
 			dialog.ShowError(err, win)
 			return
@@ -39,13 +50,20 @@ func NewDotfilesTab(win fyne.Window, b *bridge.Bridge) fyne.CanvasObject {
 			name := box.Objects[0].(*widget.Label)
 			author := box.Objects[1].(*widget.Label)
 			branch := box.Objects[2].(*widget.Label)
-			install := box.Objects[3].(*widget.Button)
-			update := box.Objects[4].(*widget.Button)
+			tags := box.Objects[3].(*widget.Label)
+			reqs := box.Objects[4].(*widget.Label)
+			install := box.Objects[5].(*widget.Button)
+			update := box.Objects[6].(*widget.Button)
 
 			entry := entries[i]
 			name.SetText(fmt.Sprintf("Name: %s", entry.Name))
+			if entry.Notice != "" {
+				name.SetText(fmt.Sprintf("Name: %s (⚠️ %s)", entry.Name, entry.Notice))
+			}
 			author.SetText(fmt.Sprintf("Author: %s", entry.Author))
 			branch.SetText(fmt.Sprintf("Branch: %s", entry.Branch))
+			tags.SetText(fmt.Sprintf("Tags: %s | Distros: %s", orDash(strings.Join(entry.Tags, ", ")), orDash(strings.Join(entry.Distros, ", "))))
+			reqs.SetText(fmt.Sprintf("Hyprland: %s | License: %s | Packages: %s", orDash(entry.Hyprland), orDash(entry.License), orDash(strings.Join(entry.Packages, " "))))
 
 			install.OnTapped = func() {
 				go func() {
@@ -72,5 +90,15 @@ func NewDotfilesTab(win fyne.Window, b *bridge.Bridge) fyne.CanvasObject {
 		list.Refresh()
 	})
 
-	return container.NewBorder(container.NewVBox(refresh), nil, nil, nil, list)
+	search.OnSubmitted = func(string) { refresh.OnTapped() }
+	filters := container.NewGridWithColumns(2, tagFilter, distroFilter)
+	return container.NewBorder(container.NewVBox(search, filters, refresh), nil, nil, nil, list)
+}
+
+// orDash boş alanlar için "-" gösterir.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
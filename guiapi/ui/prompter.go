@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/hyprcommunity/hypr-release/api/releases/prompt"
+)
+
+// DialogPrompter install/update sorularını Fyne dialoglarıyla sorar.
+// Metotları bloklar; bu yüzden UI thread'inden değil, backend işini
+// yürüten goroutine'den çağrılmalıdır.
+type DialogPrompter struct {
+	win fyne.Window
+}
+
+// NewDialogPrompter: win üzerinde dialog açan bir prompt.Prompter döndürür.
+func NewDialogPrompter(win fyne.Window) *DialogPrompter {
+	return &DialogPrompter{win: win}
+}
+
+func (d *DialogPrompter) Confirm(key, question string, def bool) (bool, error) {
+	answer := make(chan bool, 1)
+	fyne.Do(func() {
+		dialog.ShowConfirm("Confirm", question, func(ok bool) { answer <- ok }, d.win)
+	})
+	return <-answer, nil
+}
+
+func (d *DialogPrompter) Input(key, question, def string) (string, error) {
+	entry := widget.NewEntry()
+	entry.SetText(def)
+	return d.form(question, entry, func() string { return entry.Text })
+}
+
+func (d *DialogPrompter) Select(key, question string, options []string) (int, error) {
+	sel := widget.NewSelect(options, nil)
+	if len(options) > 0 {
+		sel.SetSelectedIndex(0)
+	}
+	choice, err := d.form(question, sel, func() string { return fmt.Sprint(sel.SelectedIndex()) })
+	if err != nil {
+		return -1, err
+	}
+	var index int
+	fmt.Sscanf(choice, "%d", &index)
+	return index, nil
+}
+
+// form tek alanlı bir form dialogu açar ve onaylanınca value() döndürür.
+func (d *DialogPrompter) form(question string, field fyne.CanvasObject, value func() string) (string, error) {
+	type result struct {
+		value string
+		ok    bool
+	}
+	answer := make(chan result, 1)
+	fyne.Do(func() {
+		items := []*widget.FormItem{widget.NewFormItem("", field)}
+		dialog.ShowForm(question, "OK", "Cancel", items, func(ok bool) {
+			answer <- result{value: value(), ok: ok}
+		}, d.win)
+	})
+	r := <-answer
+	if !r.ok {
+		return "", prompt.ErrCanceled
+	}
+	return r.value, nil
+}
+
+var _ prompt.Prompter = (*DialogPrompter)(nil)
@@ -21,7 +21,7 @@ func NewUpdatesTab(win fyne.Window, b *bridge.Bridge) fyne.CanvasObject {
 	checkBtn := widget.NewButton("Check Release", func() {
 		progress.Show()
 		progress.SetValue(0.3)
-		version, err := b.CheckRelease("HyDE", "/tmp") // örnek dotfile ve repoPath
+		version, err := b.CheckRelease("HyDE", "") // örnek dotfile; depo önbelleği kullanılır
 		if err != nil {
 			dialog.ShowError(err, win)
 			progress.Hide()